@@ -14,7 +14,11 @@ Commands that gofile can process are:
 - mkdir: Creates a directory
 - remove: Removes files and directories
 - gzip: GZips files in place
+- list: Lists the files that the other commands would process
+- grep: Searches files for a regular expression
+- replace: Replaces text in files
+- template: Renders Go templates
 
 For complete documentation of the command-line tool, see the README file.
- */
+*/
 package main
@@ -1,8 +1,10 @@
 package exec
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/goradd/gofile/internal/cmd"
 )
@@ -12,12 +14,16 @@ func Execute() {
 
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, err.Error())
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
-	err = rootCmd.Execute()
+
+	// an interrupt cancels the command, which stops long-running copies and commands cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err = cmd.Execute(ctx, rootCmd)
+	stop()
 
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, err.Error())
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -4,9 +4,14 @@ require github.com/spf13/cobra v1.8.0
 
 require github.com/andybalholm/brotli v1.0.6
 
+require golang.org/x/term v0.15.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 )
 
 go 1.20
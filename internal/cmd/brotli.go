@@ -9,47 +9,68 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	brotlilib "github.com/andybalholm/brotli"
+	"github.com/goradd/gofile/pkg/sys"
 	"github.com/spf13/cobra"
 )
 
-func brotli(_ *cobra.Command, _ []string) error {
+func brotli(cmd *cobra.Command, _ []string) error {
 	if len(files) == 0 {
-		if verbose {
-			fmt.Printf("No source files were specified in a gzip operation.")
-		}
+		events.logf("No source files were specified in a brotli operation.")
 		return nil
 	}
 
+	var bar *progressBar
+	if w := progressOutput(cmd); w != nil {
+		bar = newProgressBar(w, len(files), fileSizes(files))
+		defer bar.finish()
+	}
+
 	for _, f := range files {
-		if err := brotliFile(f); err != nil {
-			if filepath.Ext(f) == ".br" {
-				continue // do not compress a file that is already compressed
+		var size int64
+		if info, err := os.Stat(f); err == nil {
+			size = info.Size()
+		}
+		if filepath.Ext(f) == ".br" {
+			bar.add(1, size)
+			continue // do not compress a file that is already compressed
+		}
+		if dryRun {
+			events.Emit(sys.Event{Op: opBrotli, Source: absPath(f), Destination: absPath(f) + ".br"})
+			if deleteAfterZip {
+				events.Emit(sys.Event{Op: opRemove, Source: absPath(f)})
 			}
-			return fmt.Errorf("error compressing file %s: %s", f, err.Error())
+			continue
+		}
+		start := time.Now()
+		n, err := brotliFile(f)
+		if err != nil {
+			return fmt.Errorf("error compressing file %s: %w", f, err)
 		}
+		events.Emit(sys.Event{Op: opBrotli, Source: f, Destination: f + ".br", Bytes: n, Duration: time.Since(start)})
 		if deleteAfterZip {
 			if err := os.Remove(f); err != nil {
-				return fmt.Errorf("error deleting file %s: %s", f, err.Error())
+				return fmt.Errorf("error deleting file %s: %w", f, err)
 			}
+			events.Emit(sys.Event{Op: opRemove, Source: f})
 		}
-		if verbose {
-			fmt.Printf("Brotli compressed %s\n", f)
-		}
+		bar.add(1, size)
 	}
 	return nil
 }
 
-func brotliFile(fileName string) error {
+// brotliFile compresses the file, returning the size of the compressed file.
+func brotliFile(fileName string) (n int64, err error) {
 
 	if brotliCompressionLevel < 0 || brotliCompressionLevel > 11 {
-		return fmt.Errorf("compression level must be between 0 and 11")
+		return 0, fmt.Errorf("compression level must be between 0 and 11")
 	}
 
 	f, err := os.Create(fileName + ".br")
 	if err != nil {
-		return err
+		return
 	}
 	defer func() {
 		_ = f.Close()
@@ -58,7 +79,7 @@ func brotliFile(fileName string) error {
 	var r *os.File
 	r, err = os.Open(fileName)
 	if err != nil {
-		return err
+		return
 	}
 	defer func() {
 		_ = r.Close()
@@ -69,8 +90,10 @@ func brotliFile(fileName string) error {
 	w := brotlilib.NewWriterLevel(f, brotliCompressionLevel)
 	_, err = w.Write(buf)
 	if err != nil {
-		return err
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
 	}
-	err = w.Close()
-	return err
+	return f.Seek(0, io.SeekCurrent)
 }
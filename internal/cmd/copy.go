@@ -5,21 +5,23 @@
 package cmd
 
 import (
-	"fmt"
-	"io"
-	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/goradd/gofile/pkg/sys"
 	"github.com/spf13/cobra"
 )
 
 func copyFiles(cmd *cobra.Command, args []string) error {
-	// Cobra will guarantee we have at least 2 arguments
+	// Cobra will guarantee we have at least 2 arguments, or the destination if the sources are given with --from
 	dest := args[len(args)-1]
 	dest = processFileArg(dest)
 
 	args = args[:len(args)-1]
-	processFileListArgs(cmd, args) // puts the list of files in the files global
+	// puts the list of files in the files global. Directories are filtered as they are copied.
+	if err := processFileList(args, true); err != nil {
+		return err
+	}
 
 	var overwrite = sys.CopyDoNotOverwrite
 
@@ -30,21 +32,49 @@ func copyFiles(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(files) == 0 {
-		if verbose {
-			fmt.Printf("No source files were specified in a copy operation.")
-		}
+		events.logf("No source files were specified in a copy operation.")
 		return nil
 	}
 
-	if !verbose {
-		log.SetOutput(io.Discard)
+	if dryRun {
+		// report the actions using absolute paths
+		for i, f := range files {
+			files[i] = absPath(f)
+		}
+		if dest != "" && os.IsPathSeparator(dest[len(dest)-1]) {
+			dest = absPath(dest) + string(filepath.Separator)
+		} else {
+			dest = absPath(dest)
+		}
 	}
 
-	err := sys.CopyFilesEx(dest, overwrite, excludes, files...)
+	opts := sys.CopyOptions{
+		Overwrite:   overwrite,
+		Excludes:    excludes,
+		Includes:    includes,
+		IgnoreFiles: matcher.IgnoreFiles,
+		Filter:      matcher.Filter,
+		Hidden:      matcher.Hidden,
+		DryRun:      dryRun,
+		Emitter:     events,
+	}
+	if w := progressOutput(cmd); w != nil {
+		// the totals are not known until the copy has read every directory, so they grow as it goes
+		bar := newProgressBar(w, 0, 0)
+		defer bar.finish()
+		opts.Progress = func(p sys.CopyProgress) {
+			bar.setTotals(p.FilesFound, p.BytesFound)
+			bar.set(p.FilesDone, p.TotalBytes)
+		}
+	}
 
+	result, err := sys.Copy(cmd.Context(), dest, files, opts)
+	events.copyResult = &result
 	if err != nil {
 		return err
 	}
 
+	events.logf("Copied %d files (%d bytes), skipped %d files, created %d directories\n",
+		len(result.Copied), result.BytesWritten, len(result.Skipped), len(result.DirsCreated))
 	return nil
 }
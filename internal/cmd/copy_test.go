@@ -122,3 +122,70 @@ func TestModuleDirectoryCopy(t *testing.T) {
 		t.Fatal("test directory not detected")
 	}
 }*/
+
+func TestCopyIncludeAndNegate(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "gofileIncludeTest")
+	_ = os.RemoveAll(dir)
+	if err := os.Mkdir(dir, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd, _ := MakeRootCommand()
+	cmd.SetArgs([]string{"copy", "-x", "*.txt;!/a/t1.txt", "testdata/copytest", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for f, want := range map[string]bool{
+		"copytest/a/t1.txt":   true,
+		"copytest/b/t1.txt":   false,
+		"copytest/c/t1.txt":   false,
+		"copytest/c/e/no.abc": true,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f))); (err == nil) != want {
+			t.Errorf("%s copied: %v, want %v", f, err == nil, want)
+		}
+	}
+
+	_ = os.RemoveAll(filepath.Join(dir, "copytest"))
+	cmd, _ = MakeRootCommand()
+	cmd.SetArgs([]string{"copy", "-i", "/c/e/*.abc", "testdata/copytest", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "copytest", "c", "e", "no.abc")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "copytest", "c", "t1.txt")); err == nil {
+		t.Error("A file that is not included was copied")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "copytest", "a")); err == nil {
+		t.Error("A directory that cannot contain included files was copied")
+	}
+}
+
+func TestCopyBraceAndRegex(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "gofileBraceTest")
+	_ = os.RemoveAll(dir)
+	if err := os.Mkdir(dir, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd, _ := MakeRootCommand()
+	cmd.SetArgs([]string{"copy", "-x", "/{a,b};re:^c/e/.*\\.abc$", "testdata/copytest", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for f, want := range map[string]bool{
+		"copytest/a/t1.txt":    false,
+		"copytest/b/t1.txt":    false,
+		"copytest/c/t1.txt":    true,
+		"copytest/c/e/yes.txt": true,
+		"copytest/c/e/no.abc":  false,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f))); (err == nil) != want {
+			t.Errorf("%s copied: %v, want %v", f, err == nil, want)
+		}
+	}
+}
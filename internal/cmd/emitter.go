@@ -0,0 +1,227 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/goradd/gofile/pkg/sys"
+	"github.com/spf13/cobra"
+)
+
+// Operations reported by the commands in addition to the ones reported by sys.
+const (
+	opRemove   = "remove"
+	opGzip     = "gzip"
+	opBrotli   = "brotli"
+	opGenerate = "generate"
+	opPath     = "path"
+	opGrep     = "grep"
+	opReplace  = "replace"
+	opTemplate = "template"
+	opSummary  = "summary"
+)
+
+// jsonEvent is the json representation of a sys.Event. Durations are in nanoseconds.
+type jsonEvent struct {
+	Op          string `json:"op"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Bytes       int64  `json:"bytes,omitempty"`
+	Skipped     string `json:"skipped,omitempty"`
+	Duration    int64  `json:"duration,omitempty"`
+	Error       string `json:"error,omitempty"`
+	DryRun      bool   `json:"dryRun,omitempty"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+// jsonSummary is the final object written after a command finishes in json mode.
+type jsonSummary struct {
+	Op       string `json:"op"`
+	Command  string `json:"command"`
+	Actions  int    `json:"actions"`
+	Skipped  int    `json:"skipped"`
+	Errors   int    `json:"errors"`
+	Bytes    int64  `json:"bytes"`
+	Duration int64  `json:"duration"`
+	// Result is the result of a copy command.
+	Result *sys.CopyResult `json:"result,omitempty"`
+}
+
+// emitter is the single place that commands report what they do. In text mode, actions are printed when
+// verbose or dryRun are set. In json mode, every event is written as a json object on its own line.
+type emitter struct {
+	out     io.Writer
+	errOut  io.Writer
+	json    bool
+	start   time.Time
+	summary jsonSummary
+	// copyResult is set by commands that copy files
+	copyResult *sys.CopyResult
+	// finished is set once the summary is written
+	finished bool
+}
+
+// events is the emitter for the currently executing command.
+var events = &emitter{}
+
+func newEmitter(cmd *cobra.Command) *emitter {
+	return &emitter{
+		out:     cmd.OutOrStdout(),
+		errOut:  cmd.ErrOrStderr(),
+		json:    jsonOutput,
+		start:   time.Now(),
+		summary: jsonSummary{Op: opSummary, Command: cmd.Name()},
+	}
+}
+
+// Emit reports an event. It satisfies the sys.Emitter interface.
+func (e *emitter) Emit(ev sys.Event) {
+	ev.DryRun = ev.DryRun || dryRun
+	switch {
+	case ev.Err != nil:
+		e.summary.Errors++
+	case ev.Skipped != "":
+		e.summary.Skipped++
+	default:
+		e.summary.Actions++
+		e.summary.Bytes += ev.Bytes
+	}
+
+	if e.json {
+		j := jsonEvent{
+			Op:          ev.Op,
+			Source:      ev.Source,
+			Destination: ev.Destination,
+			Bytes:       ev.Bytes,
+			Skipped:     ev.Skipped,
+			Duration:    int64(ev.Duration),
+			DryRun:      ev.DryRun,
+			Detail:      ev.Detail,
+		}
+		if ev.Err != nil {
+			j.Error = ev.Err.Error()
+		}
+		e.writeJson(j)
+		return
+	}
+
+	if ev.Op == opPath {
+		_, _ = fmt.Fprint(e.out, ev.Destination)
+	} else if verbose || (ev.DryRun && ev.Skipped == "" && ev.Err == nil) {
+		_, _ = fmt.Fprintln(e.out, eventText(ev))
+	}
+}
+
+// logf prints an informational message to stderr in verbose mode, so that stdout only contains the output of the
+// command.
+func (e *emitter) logf(format string, args ...any) {
+	if !verbose {
+		return
+	}
+	_, _ = fmt.Fprintf(e.errOut, format, args...)
+}
+
+// finish writes the summary in json mode. It only writes it once.
+func (e *emitter) finish() {
+	if e.finished {
+		return
+	}
+	e.finished = true
+	if e.json {
+		e.summary.Duration = int64(time.Since(e.start))
+		e.summary.Result = e.copyResult
+		e.writeJson(e.summary)
+	}
+}
+
+// fail reports the error that stopped cmd as an event in json mode, and then writes the summary.
+func (e *emitter) fail(cmd *cobra.Command, err error) {
+	if e.json {
+		e.Emit(sys.Event{Op: cmd.Name(), Err: err})
+	}
+	e.finish()
+}
+
+func (e *emitter) writeJson(v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintln(e.out, string(b))
+}
+
+// eventText returns the text description of events generated by the commands.
+func eventText(ev sys.Event) string {
+	if ev.Err != nil || ev.Skipped != "" {
+		return ev.String()
+	}
+	switch ev.Op {
+	case opRemove:
+		if ev.DryRun {
+			return "Would remove " + ev.Source
+		}
+		return "Removed " + ev.Source
+	case opGzip:
+		if ev.DryRun {
+			return "Would compress " + ev.Source + " to " + ev.Destination
+		}
+		return "Zipped " + ev.Source
+	case opBrotli:
+		if ev.DryRun {
+			return "Would compress " + ev.Source + " to " + ev.Destination
+		}
+		return "Brotli compressed " + ev.Source
+	case opReplace:
+		if ev.DryRun {
+			return "Would make " + ev.Detail + " replacements in " + ev.Source
+		}
+		return ev.Source + ": " + ev.Detail + " replacements"
+	case opTemplate:
+		if ev.DryRun {
+			return "Would render " + ev.Source + " to " + ev.Destination
+		}
+		return "Rendered " + ev.Source + " to " + ev.Destination
+	case opGenerate:
+		if ev.DryRun {
+			return "Would run go generate " + ev.Source
+		}
+		return "Generated " + ev.Source + ": " + ev.Detail
+	}
+	return ev.String()
+}
+
+// withEvents wraps a command so that an error is reported as an event and the summary is written when it
+// finishes.
+func withEvents(f func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		err := f(cmd, args)
+		if err != nil {
+			events.fail(cmd, err)
+		} else {
+			events.finish()
+		}
+		return err
+	}
+}
+
+// Execute executes the command tree made by MakeRootCommand. An error that stops a command before it runs, like an
+// invalid pattern or a missing argument, is reported the same way as an error returned by the command, so that json
+// output always ends with a summary.
+func Execute(ctx context.Context, rootCmd *cobra.Command) error {
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	if err != nil && !events.finished {
+		if events.summary.Command == "" {
+			// the error happened before persistentPreRunE made the emitter
+			events = newEmitter(cmd)
+		}
+		events.fail(cmd, err)
+	}
+	return err
+}
@@ -5,7 +5,7 @@
 package cmd
 
 import (
-	"fmt"
+	"time"
 
 	"github.com/goradd/gofile/pkg/sys"
 	"github.com/spf13/cobra"
@@ -13,15 +13,19 @@ import (
 
 var generateResult []byte
 
-func generateFiles(_ *cobra.Command, _ []string) error {
+func generateFiles(cmd *cobra.Command, _ []string) error {
 	for _, f := range files {
+		if dryRun {
+			events.Emit(sys.Event{Op: opGenerate, Source: absPath(f)})
+			continue
+		}
 		var err error
-		generateResult, err = sys.ExecuteShellCommand("go generate " + f)
+		start := time.Now()
+		generateResult, err = sys.ExecuteShellCommandContext(cmd.Context(), "go generate "+f)
 		if err != nil {
 			return err
-		} else if verbose {
-			fmt.Printf("Generated %s: %s\n", f, string(generateResult))
 		}
+		events.Emit(sys.Event{Op: opGenerate, Source: f, Duration: time.Since(start), Detail: string(generateResult)})
 	}
 	return nil
 }
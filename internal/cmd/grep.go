@@ -0,0 +1,161 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goradd/gofile/pkg/sys"
+	"github.com/spf13/cobra"
+)
+
+var grepIgnoreCase bool
+var grepFilesOnly bool
+var grepCount bool
+var grepFailIfFound bool
+var grepFailIfMissing bool
+var grepRegex *regexp.Regexp
+
+// binarySniffLen is how much of a file is checked for a NUL byte to decide whether it is binary, the same as git.
+const binarySniffLen = 8000
+
+// grepLine is a line that matched.
+type grepLine struct {
+	n    int
+	text string
+}
+
+// grepResult is the result of searching one file.
+type grepResult struct {
+	lines  []grepLine
+	count  int
+	binary bool
+	err    error
+}
+
+// processGrepArgs compiles the regular expression in the first argument, and expands the rest of the arguments
+// the way gzip does.
+func processGrepArgs(cmd *cobra.Command, args []string) (err error) {
+	if grepFailIfFound && grepFailIfMissing {
+		return fmt.Errorf("--fail-if-found and --fail-if-missing cannot be used together")
+	}
+	expr := args[0]
+	if grepIgnoreCase {
+		expr = "(?i)" + expr
+	}
+	if grepRegex, err = regexp.Compile(expr); err != nil {
+		return fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return processExpandedFileListArgs(cmd, args[1:])
+}
+
+func grep(cmd *cobra.Command, args []string) error {
+	// the arguments have been checked, so a failed search should not print the usage
+	cmd.SilenceUsage = true
+
+	results := make([]grepResult, len(files))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = grepFile(files[i], grepRegex)
+			}
+		}()
+	}
+	for i := range files {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	// report in the order of the files, so the output does not depend on the timing of the search
+	out := cmd.OutOrStdout()
+	var found int
+	for i, f := range files {
+		r := results[i]
+		switch {
+		case r.err != nil:
+			return fmt.Errorf("error searching file %s: %w", f, r.err)
+		case r.binary:
+			events.logf("Skipped binary file %s\n", f)
+			continue
+		}
+		found += r.count
+		switch {
+		case grepCount:
+			grepReport(out, f, strconv.Itoa(r.count))
+		case r.count == 0:
+		case grepFilesOnly:
+			grepReport(out, f, "")
+		default:
+			for _, l := range r.lines {
+				grepReport(out, f, strconv.Itoa(l.n)+":"+l.text)
+			}
+		}
+	}
+
+	if grepFailIfFound && found > 0 {
+		return &exitError{code: exitCheckFailed, err: fmt.Errorf("found %d matches of %s", found, args[0])}
+	}
+	if grepFailIfMissing && found == 0 {
+		return &exitError{code: exitCheckFailed, err: fmt.Errorf("no matches of %s were found", args[0])}
+	}
+	return nil
+}
+
+// grepReport writes a match of file f. detail is the line number and text of the match, or the count of matches,
+// or empty if only the file is reported.
+func grepReport(out io.Writer, f string, detail string) {
+	if events.json {
+		events.Emit(sys.Event{Op: opGrep, Source: f, Detail: detail})
+		return
+	}
+	events.summary.Actions++
+	if detail == "" {
+		_, _ = fmt.Fprintln(out, f)
+	} else {
+		_, _ = fmt.Fprintln(out, f+":"+detail)
+	}
+}
+
+// grepFile searches the file for lines that match re.
+func grepFile(f string, re *regexp.Regexp) (r grepResult) {
+	data, err := os.ReadFile(f)
+	if err != nil {
+		r.err = err
+		return
+	}
+	if isBinary(data) {
+		r.binary = true
+		return
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if re.MatchString(line) {
+			r.count++
+			r.lines = append(r.lines, grepLine{n: i + 1, text: line})
+		}
+	}
+	return
+}
+
+// isBinary returns true if the file content looks like a binary file rather than text.
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
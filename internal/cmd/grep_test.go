@@ -0,0 +1,66 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeGrepTree(t *testing.T) string {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a.tmpl":     "title\r\n{{/* TODO(release) */}}\r\nbody\r\n",
+		"b.tmpl":     "nothing here\n",
+		"web/app.js": "fetch('http://localhost:8000')\nfetch('/api')\nLOCALHOST\n",
+		"web/bin.js": "localhost\x00\x01",
+	})
+	return dir
+}
+
+func TestGrep(t *testing.T) {
+	dir := makeGrepTree(t)
+	js := filepath.Join(dir, "web", "app.js")
+
+	out, err := runCommand("grep", "localhost", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != js+":1:fetch('http://localhost:8000')\n" {
+		t.Errorf("Got %q", out)
+	}
+
+	out, _ = runCommand("grep", "--ignore-case", "-c", "localhost", filepath.Join(dir, "web"))
+	if out != js+":2\n" {
+		t.Errorf("Got %q", out)
+	}
+
+	out, _ = runCommand("grep", "-l", "-i", "*.tmpl", `TODO\(release\)`, dir)
+	if out != filepath.Join(dir, "a.tmpl")+"\n" {
+		t.Errorf("Got %q", out)
+	}
+}
+
+func TestGrepExitCodes(t *testing.T) {
+	dir := makeGrepTree(t)
+
+	_, err := runCommand("grep", "--fail-if-found", "-x", "web", `TODO\(release\)`, dir)
+	if err == nil || ExitCode(err) != exitCheckFailed || !strings.Contains(err.Error(), "found 1 match") {
+		t.Errorf("Expected a failed check, got %v", err)
+	}
+	if _, err = runCommand("grep", "--fail-if-found", "localhost", filepath.Join(dir, "*.tmpl")); err != nil {
+		t.Error(err)
+	}
+	if _, err = runCommand("grep", "--fail-if-missing", "localhost", filepath.Join(dir, "*.tmpl")); ExitCode(err) != exitCheckFailed {
+		t.Errorf("Expected a failed check, got %v", err)
+	}
+	if _, err = runCommand("grep", "--fail-if-missing", "localhost", dir); err != nil {
+		t.Error(err)
+	}
+	if _, err = runCommand("grep", "a(", dir); err == nil || ExitCode(err) != exitFailed {
+		t.Errorf("Expected an invalid expression error, got %v", err)
+	}
+}
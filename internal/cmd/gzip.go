@@ -10,41 +10,62 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/goradd/gofile/pkg/sys"
 	"github.com/spf13/cobra"
 )
 
-func gzip(_ *cobra.Command, _ []string) error {
+func gzip(cmd *cobra.Command, _ []string) error {
 	if len(files) == 0 {
-		if verbose {
-			fmt.Printf("No source files were specified in a gzip operation.")
-		}
+		events.logf("No source files were specified in a gzip operation.")
 		return nil
 	}
 
+	var bar *progressBar
+	if w := progressOutput(cmd); w != nil {
+		bar = newProgressBar(w, len(files), fileSizes(files))
+		defer bar.finish()
+	}
+
 	for _, f := range files {
-		if err := zipFile(f); err != nil {
-			if filepath.Ext(f) == ".gz" {
-				continue // do not compress a file that is already compressed
+		var size int64
+		if info, err := os.Stat(f); err == nil {
+			size = info.Size()
+		}
+		if filepath.Ext(f) == ".gz" {
+			bar.add(1, size)
+			continue // do not compress a file that is already compressed
+		}
+		if dryRun {
+			events.Emit(sys.Event{Op: opGzip, Source: absPath(f), Destination: absPath(f) + ".gz"})
+			if deleteAfterZip {
+				events.Emit(sys.Event{Op: opRemove, Source: absPath(f)})
 			}
-			return fmt.Errorf("error zipping file %s: %s", f, err.Error())
+			continue
+		}
+		start := time.Now()
+		n, err := zipFile(f)
+		if err != nil {
+			return fmt.Errorf("error zipping file %s: %w", f, err)
 		}
+		events.Emit(sys.Event{Op: opGzip, Source: f, Destination: f + ".gz", Bytes: n, Duration: time.Since(start)})
 		if deleteAfterZip {
 			if err := os.Remove(f); err != nil {
-				return fmt.Errorf("error deleting file %s: %s", f, err.Error())
+				return fmt.Errorf("error deleting file %s: %w", f, err)
 			}
+			events.Emit(sys.Event{Op: opRemove, Source: f})
 		}
-		if verbose {
-			fmt.Printf("Zipped %s\n", f)
-		}
+		bar.add(1, size)
 	}
 	return nil
 }
 
-func zipFile(fileName string) error {
+// zipFile compresses the file, returning the size of the compressed file.
+func zipFile(fileName string) (n int64, err error) {
 	f, err := os.Create(fileName + ".gz")
 	if err != nil {
-		return err
+		return
 	}
 	defer func() {
 		_ = f.Close()
@@ -53,7 +74,7 @@ func zipFile(fileName string) error {
 	var r *os.File
 	r, err = os.Open(fileName)
 	if err != nil {
-		return err
+		return
 	}
 	defer func() {
 		_ = r.Close()
@@ -64,12 +85,14 @@ func zipFile(fileName string) error {
 	var w *ziplib.Writer
 	w, err = ziplib.NewWriterLevel(f, gzipCompressionLevel)
 	if err != nil {
-		return err
+		return
 	}
 	_, err = w.Write(buf)
 	if err != nil {
-		return err
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
 	}
-	err = w.Close()
-	return err
+	return f.Seek(0, io.SeekCurrent)
 }
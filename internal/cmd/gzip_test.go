@@ -153,3 +153,67 @@ func TestGZipRecurse(t *testing.T) {
 		t.Error("Zip file not created")
 	}
 }
+
+func TestGZipRespectIgnore(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gitignore":    "*.log\n",
+		".gofileignore": "*.bak\n",
+		"a.txt":         "a",
+		"b.log":         "b",
+		"c.bak":         "c",
+	})
+
+	cmd, _ := MakeRootCommand()
+	cmd.SetArgs([]string{"gzip", "--respect-ignore", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"a.txt.gz": true, "b.log.gz": false, "c.bak.gz": false, ".gitignore.gz": false, ".gofileignore.gz": false} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != want {
+			t.Errorf("%s exists: %v, want %v", name, err == nil, want)
+		}
+	}
+}
+
+func TestGZipIgnoreOptIn(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gofileignore": "*.bak\n",
+		"c.bak":         "c",
+	})
+
+	// ignore files are only read with --respect-ignore
+	cmd, _ := MakeRootCommand()
+	cmd.SetArgs([]string{"gzip", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.bak.gz")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGZipHidden(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{".DS_Store": "test", "app.js": "test"})
+	cmd, _ := MakeRootCommand()
+	cmd.SetArgs([]string{"gzip", "--hidden", "exclude", filepath.Join(dir, "*")})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app.js.gz")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".DS_Store.gz")); err == nil {
+		t.Error("A hidden file was compressed")
+	}
+
+	cmd, _ = MakeRootCommand()
+	cmd.SetArgs([]string{"gzip", "--hidden", "maybe", dir})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err == nil {
+		t.Error("Expected an invalid policy error")
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var listRecursive bool
+var listFormat string
+var listRelativeTo string
+
+// Formats of the list command.
+const (
+	listNative = "native"
+	listSlash  = "slash"
+	listJson   = "json"
+)
+
+// listEntry is the json representation of a file printed by the list command.
+type listEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	IsDir   bool      `json:"isDir,omitempty"`
+}
+
+// processListArgs checks the list flags, and then expands the arguments the way the commands that list would
+// operate on them do. Without --recursive, that is the way copy does, which keeps the directories whatever the
+// filter flags select.
+func processListArgs(cmd *cobra.Command, args []string) error {
+	if jsonOutput && !cmd.Flags().Changed("format") {
+		listFormat = listJson
+	}
+	switch listFormat {
+	case listNative, listSlash, listJson:
+	default:
+		return fmt.Errorf("invalid format %q, use native, slash or json", listFormat)
+	}
+	if listRecursive {
+		return processExpandedFileListArgs(cmd, args)
+	}
+	return processFileList(args, true)
+}
+
+func listFiles(cmd *cobra.Command, _ []string) error {
+	var base string
+	if listRelativeTo != "" {
+		var err error
+		if base, err = filepath.Abs(processFileArg(listRelativeTo)); err != nil {
+			return err
+		}
+	}
+	out := cmd.OutOrStdout()
+	for _, f := range files {
+		info, err := os.Lstat(f)
+		if err != nil {
+			events.logf("%s does not exist\n", f)
+			continue
+		}
+		p := f
+		if base != "" {
+			if abs, err := filepath.Abs(f); err == nil {
+				if rel, err := filepath.Rel(base, abs); err == nil {
+					p = rel
+				}
+			}
+		}
+		switch listFormat {
+		case listSlash:
+			_, _ = fmt.Fprintln(out, filepath.ToSlash(p))
+		case listJson:
+			b, err := json.Marshal(listEntry{Path: p, Size: info.Size(), ModTime: info.ModTime(), IsDir: info.IsDir()})
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(out, string(b))
+		default:
+			_, _ = fmt.Fprintln(out, p)
+		}
+		events.summary.Actions++
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func runList(t *testing.T, args ...string) []string {
+	out, err := runCommand(append([]string{"list"}, args...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(out), "\n")
+}
+
+func TestList(t *testing.T) {
+	got := runList(t, "testdata/copytest", "testdata/none")
+	if !reflect.DeepEqual(got, []string{filepath.FromSlash("testdata/copytest")}) {
+		t.Errorf("Got %v", got)
+	}
+
+	// directories are kept whatever the filter selects, as copy does
+	got = runList(t, "--type", "f", "testdata/copytest", "testdata/copytest/c/*")
+	expected := []string{filepath.FromSlash("testdata/copytest"), filepath.FromSlash("testdata/copytest/c/e"), filepath.FromSlash("testdata/copytest/c/t1.txt")}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v", got)
+	}
+
+	got = runList(t, "-r", "-x", "/b", "--format", "slash", "--relative-to", "testdata", "testdata/copytest")
+	expected = []string{"copytest/a/t1.txt", "copytest/c/e/no.abc", "copytest/c/e/yes.txt", "copytest/c/t1.txt"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v", got)
+	}
+
+	// regular expressions are matched against the paths inside the directory, not the directory itself
+	got = runList(t, "-r", "-x", "re:(data|^c/)", "--format", "slash", "testdata/copytest")
+	expected = []string{"testdata/copytest/a/t1.txt", "testdata/copytest/b/t1.txt"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v", got)
+	}
+
+	got = runList(t, "-r", "--format", "json", "-i", "*.abc", "testdata/copytest")
+	if len(got) != 1 {
+		t.Fatalf("Got %v", got)
+	}
+	var e listEntry
+	if err := json.Unmarshal([]byte(got[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Path != filepath.FromSlash("testdata/copytest/c/e/no.abc") || e.ModTime.IsZero() || e.IsDir {
+		t.Errorf("Got %v", e)
+	}
+}
+
+func TestListJsonSummary(t *testing.T) {
+	cmd, _ := MakeRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list", "--json", "testdata/copytest/a/t1.txt"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var lines []map[string]any
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var m map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, m)
+	}
+	if len(lines) != 2 || lines[0]["size"] == nil || lines[1]["op"] != opSummary || lines[1]["actions"] != float64(1) {
+		t.Errorf("Got %v", lines)
+	}
+}
+
+func TestListUnreadableDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "locked"), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(filepath.Join(dir, "locked"), 0o755)
+	if _, err := os.ReadDir(filepath.Join(dir, "locked")); err == nil {
+		t.Skip("cannot make a directory unreadable")
+	}
+
+	cmd, _ := MakeRootCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"list", "-r", dir, "testdata/copytest"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("Expected an error for a directory that cannot be read")
+	}
+}
@@ -5,19 +5,22 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 
+	"github.com/goradd/gofile/pkg/sys"
 	"github.com/spf13/cobra"
 )
 
 func mkDir(_ *cobra.Command, _ []string) error {
 	for _, dir := range files {
+		if dryRun {
+			events.Emit(sys.Event{Op: sys.OpMkdir, Destination: absPath(dir)})
+			continue
+		}
 		if err := os.MkdirAll(dir, os.FileMode(0777)); err != nil {
 			return err
-		} else if verbose {
-			fmt.Printf("Created directory %s\n", dir)
 		}
+		events.Emit(sys.Event{Op: sys.OpMkdir, Destination: dir})
 	}
 	return nil
 }
@@ -5,13 +5,12 @@
 package cmd
 
 import (
-	"fmt"
-
+	"github.com/goradd/gofile/pkg/sys"
 	"github.com/spf13/cobra"
 )
 
-func outPath(cmd *cobra.Command, args []string) error {
+func outPath(_ *cobra.Command, args []string) error {
 	f := processFileArg(args[0])
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), f)
+	events.Emit(sys.Event{Op: opPath, Source: args[0], Destination: f})
 	return nil
 }
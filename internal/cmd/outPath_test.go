@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -22,4 +23,19 @@ func Test_outPath(t *testing.T) {
 	if buf.String() != file {
 		t.Error("Files do not match")
 	}
+
+	// verbose messages go to stderr, so that the path can be captured
+	cmd, _ = MakeRootCommand()
+	cmd.SetArgs([]string{"path", "-v", "github.com/goradd/gofile/internal/cmd/outPath_test.go"})
+	var errBuf bytes.Buffer
+	buf.Reset()
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+	_ = cmd.Execute()
+	if buf.String() != file {
+		t.Errorf("Got %q", buf.String())
+	}
+	if !strings.Contains(errBuf.String(), "Module path") {
+		t.Errorf("Got %q on stderr", errBuf.String())
+	}
 }
@@ -0,0 +1,170 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// progressWidth is the number of characters in the bar portion of the progress display.
+const progressWidth = 25
+
+// progressInterval is the minimum time between redraws of the progress display.
+const progressInterval = 100 * time.Millisecond
+
+// progressBar draws a single line progress display on a terminal.
+// A nil *progressBar is valid, and draws nothing.
+type progressBar struct {
+	w          io.Writer
+	totalFiles int
+	totalBytes int64
+	files      int
+	bytes      int64
+	start      time.Time
+	lastDraw   time.Time
+	lineLen    int
+}
+
+// progressOutput returns where progress should be displayed, or nil if progress should not be shown.
+// Progress is only shown when stderr is a terminal, and output is not json, verbose or a dry run.
+func progressOutput(cmd *cobra.Command) io.Writer {
+	if noProgress || jsonOutput || verbose || dryRun {
+		return nil
+	}
+	f, ok := cmd.ErrOrStderr().(*os.File)
+	if !ok || !isTerminal(f) {
+		return nil
+	}
+	return f
+}
+
+// newProgressBar returns a progress bar that writes to w and reports on the given totals.
+// If w is nil, it returns nil.
+func newProgressBar(w io.Writer, totalFiles int, totalBytes int64) *progressBar {
+	if w == nil {
+		return nil
+	}
+	return &progressBar{
+		w:          w,
+		totalFiles: totalFiles,
+		totalBytes: totalBytes,
+		start:      time.Now(),
+	}
+}
+
+// fileSizes returns the total size of the given files.
+func fileSizes(files []string) (total int64) {
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+	}
+	return
+}
+
+// isTerminal returns true if the file is a terminal or console. Other character devices, like /dev/null, are not.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// set records the number of files and bytes completed so far, and redraws the display if needed.
+func (p *progressBar) set(files int, bytes int64) {
+	if p == nil {
+		return
+	}
+	p.files = files
+	p.bytes = bytes
+	if time.Since(p.lastDraw) >= progressInterval || p.files == p.totalFiles {
+		p.draw()
+	}
+}
+
+// setTotals changes the total number of files and bytes, for work whose size is found as it goes.
+func (p *progressBar) setTotals(files int, bytes int64) {
+	if p == nil {
+		return
+	}
+	p.totalFiles = files
+	p.totalBytes = bytes
+}
+
+// add adds to the number of files and bytes completed so far.
+func (p *progressBar) add(files int, bytes int64) {
+	if p == nil {
+		return
+	}
+	p.set(p.files+files, p.bytes+bytes)
+}
+
+// finish erases the display.
+func (p *progressBar) finish() {
+	if p == nil || p.lineLen == 0 {
+		return
+	}
+	_, _ = fmt.Fprint(p.w, "\r"+strings.Repeat(" ", p.lineLen)+"\r")
+}
+
+func (p *progressBar) draw() {
+	line := p.render(time.Since(p.start))
+	pad := ""
+	if len(line) < p.lineLen {
+		pad = strings.Repeat(" ", p.lineLen-len(line))
+	}
+	_, _ = fmt.Fprint(p.w, "\r"+line+pad)
+	p.lineLen = len(line)
+	p.lastDraw = time.Now()
+}
+
+// render returns the text of the progress display after elapsed time.
+func (p *progressBar) render(elapsed time.Duration) string {
+	// measure progress by bytes if we know them, since files can vary greatly in size
+	var fraction float64
+	if p.totalBytes > 0 {
+		fraction = float64(p.bytes) / float64(p.totalBytes)
+	} else if p.totalFiles > 0 {
+		fraction = float64(p.files) / float64(p.totalFiles)
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	filled := int(fraction * progressWidth)
+
+	s := fmt.Sprintf("[%s%s] %d/%d files",
+		strings.Repeat("=", filled), strings.Repeat(" ", progressWidth-filled), p.files, p.totalFiles)
+
+	if p.totalBytes > 0 {
+		s += fmt.Sprintf("  %s/%s", formatBytes(p.bytes), formatBytes(p.totalBytes))
+		if secs := elapsed.Seconds(); secs > 0 {
+			s += fmt.Sprintf("  %s/s", formatBytes(int64(float64(p.bytes)/secs)))
+		}
+	}
+
+	if fraction > 0 && fraction < 1 {
+		eta := time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+		s += "  ETA " + eta.Round(time.Second).String()
+	}
+	return s
+}
+
+// formatBytes returns a short, human-readable version of a byte count.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
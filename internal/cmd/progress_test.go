@@ -0,0 +1,75 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	bar := newProgressBar(&buf, 4, 4096)
+	bar.files = 1
+	bar.bytes = 1024
+
+	s := bar.render(2 * time.Second)
+	want := "[======                   ] 1/4 files  1.0 KiB/4.0 KiB  512 B/s  ETA 6s"
+	if s != want {
+		t.Errorf("render() = %q, want %q", s, want)
+	}
+
+	bar.set(4, 4096)
+	bar.finish()
+	if !strings.HasSuffix(buf.String(), "\r") || !strings.Contains(buf.String(), "4/4 files") {
+		t.Errorf("Wrong output: %q", buf.String())
+	}
+
+	// the totals can grow as the work is found
+	bar = newProgressBar(&buf, 0, 0)
+	bar.setTotals(2, 2048)
+	bar.files = 1
+	bar.bytes = 1024
+	if s = bar.render(time.Second); !strings.HasPrefix(s, "[============             ] 1/2 files  1.0 KiB/2.0 KiB") {
+		t.Errorf("render() = %q", s)
+	}
+
+	// a nil bar does nothing
+	bar = newProgressBar(nil, 1, 0)
+	bar.setTotals(2, 0)
+	bar.add(1, 0)
+	bar.finish()
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("The null device is not a terminal")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+	}
+}
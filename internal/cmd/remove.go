@@ -5,27 +5,34 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
+	"time"
 
+	"github.com/goradd/gofile/pkg/sys"
 	"github.com/spf13/cobra"
 )
 
-func removeFiles(cmd *cobra.Command, args []string) error {
+func removeFiles(cmd *cobra.Command, _ []string) error {
 	if len(files) == 0 {
-		if verbose {
-			fmt.Printf("No source files were specified in a remove operation.")
-		}
+		events.logf("No source files were specified in a remove operation.")
 		return nil
 	}
 
+	bar := newProgressBar(progressOutput(cmd), len(files), 0)
+	defer bar.finish()
+
 	for _, f := range files {
+		if dryRun {
+			events.Emit(sys.Event{Op: opRemove, Source: absPath(f)})
+			continue
+		}
+		start := time.Now()
 		err := os.RemoveAll(f)
 		if err != nil {
 			return err
-		} else if verbose {
-			fmt.Printf("Removed %s\n", f)
 		}
+		events.Emit(sys.Event{Op: opRemove, Source: f, Duration: time.Since(start)})
+		bar.add(1, 0)
 	}
 	return nil
 }
@@ -0,0 +1,194 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goradd/gofile/pkg/sys"
+	"github.com/spf13/cobra"
+)
+
+var replaceRegex bool
+var replaceLiteral bool
+var replaceCheck bool
+var replaceWith *replacer
+
+// replacer makes the replacements in the content of a file.
+type replacer struct {
+	re          *regexp.Regexp
+	find        string
+	replacement string
+}
+
+// processReplaceArgs prepares the replacer from the first two arguments, and expands the rest of the arguments
+// the way gzip does.
+func processReplaceArgs(cmd *cobra.Command, args []string) error {
+	if replaceRegex && replaceLiteral {
+		return fmt.Errorf("--regex and --literal cannot be used together")
+	}
+	if args[0] == "" {
+		return fmt.Errorf("the text to find cannot be empty")
+	}
+	var err error
+	if replaceWith, err = newReplacer(args[0], args[1], replaceRegex); err != nil {
+		return err
+	}
+	return processExpandedFileListArgs(cmd, args[2:])
+}
+
+// newReplacer returns a replacer for the find and replacement arguments.
+func newReplacer(find, replacement string, regex bool) (r *replacer, err error) {
+	r = &replacer{find: find}
+	if regex {
+		if r.re, err = regexp.Compile(find); err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	r.replacement, err = r.expand(replacement)
+	return
+}
+
+// expand returns the replacement with the environment variables written as ${NAME} replaced by their values.
+// It is an error if one of the variables is not set. $$ is a single $, and any other $ is left alone, except that
+// with a regular expression, $1, ${1} and the names of its groups refer to the groups, and the $ in $$ and in the
+// values of the variables is escaped so that it is not mistaken for a reference to a group.
+func (r *replacer) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		if s[i+1] == '$' {
+			b.WriteString(r.escape("$"))
+			i++
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if s[i+1] != '{' || end < 0 || !isEnvName(s[i+2:i+end]) || r.re != nil && r.isGroup(s[i+2:i+end]) {
+			b.WriteByte('$')
+			continue
+		}
+		name := s[i+2 : i+end]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("the environment variable %s is not set", name)
+		}
+		b.WriteString(r.escape(v))
+		i += end
+	}
+	return b.String(), nil
+}
+
+// escape escapes the $ in s so that it is not mistaken for a reference to a group of the regular expression.
+func (r *replacer) escape(s string) string {
+	if r.re == nil {
+		return s
+	}
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// isEnvName returns true if name can be the name of an environment variable.
+func isEnvName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isGroup returns true if name is the number or name of a group of the regular expression.
+func (r *replacer) isGroup(name string) bool {
+	if name != "" && strings.Trim(name, "0123456789") == "" {
+		return true
+	}
+	for _, n := range r.re.SubexpNames() {
+		if n != "" && n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// replace returns the content with the replacements made, and the number of replacements.
+// If every line of the content ends with \r\n, the replacement is made as if the lines ended with \n,
+// and the \r\n line endings are restored afterwards, so that new lines in the replacement match the file.
+func (r *replacer) replace(content string) (string, int) {
+	crlf := strings.Contains(content, "\r\n") && strings.Count(content, "\r\n") == strings.Count(content, "\n")
+	if crlf {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	var n int
+	if r.re == nil {
+		n = strings.Count(content, r.find)
+		content = strings.ReplaceAll(content, r.find, r.replacement)
+	} else {
+		matches := r.re.FindAllStringSubmatchIndex(content, -1)
+		n = len(matches)
+		var dst []byte
+		last := 0
+		for _, m := range matches {
+			dst = append(dst, content[last:m[0]]...)
+			dst = r.re.ExpandString(dst, r.replacement, content, m)
+			last = m[1]
+		}
+		content = string(append(dst, content[last:]...))
+	}
+	if crlf {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	return content, n
+}
+
+func replaceInFiles(cmd *cobra.Command, _ []string) error {
+	// the arguments have been checked, so a failed check should not print the usage
+	cmd.SilenceUsage = true
+
+	out := cmd.OutOrStdout()
+	var changed int
+	for _, f := range files {
+		start := time.Now()
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("error reading file %s: %w", f, err)
+		}
+		if isBinary(data) {
+			events.logf("Skipped binary file %s\n", f)
+			continue
+		}
+		content, n := replaceWith.replace(string(data))
+		if content == string(data) {
+			continue
+		}
+		changed++
+		switch {
+		case replaceCheck:
+			_, _ = fmt.Fprintf(out, "%s: %d replacements would be made\n", f, n)
+		case dryRun:
+			events.Emit(sys.Event{Op: opReplace, Source: absPath(f), Detail: fmt.Sprint(n)})
+		default:
+			if err = sys.WriteFileAtomic(f, []byte(content), 0o644); err != nil {
+				return fmt.Errorf("error writing file %s: %w", f, err)
+			}
+			events.Emit(sys.Event{Op: opReplace, Source: f, Detail: fmt.Sprint(n), Duration: time.Since(start)})
+			if !events.json && !verbose {
+				_, _ = fmt.Fprintf(out, "%s: %d replacements\n", f, n)
+			}
+		}
+	}
+	if replaceCheck && changed > 0 {
+		return &exitError{code: exitCheckFailed, err: fmt.Errorf("%d files would change", changed)}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	html := filepath.Join(dir, "index.html")
+	js := filepath.Join(dir, "app.js")
+	bin := filepath.Join(dir, "app.bin")
+	if err := os.WriteFile(html, []byte("<p>0.0.0</p>\r\n<p>0.0.0</p>\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(js, []byte("const v1 = 'a';\nconst v22 = 'b';\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bin, []byte("0.0.0\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOFILE_VERSION", "1.2.3")
+	t.Setenv("GOFILE_PRICE", "$5")
+
+	out, err := runCommand("replace", "0.0.0", "${GOFILE_VERSION}\n", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != html+": 2 replacements\n" {
+		t.Errorf("Got %q", out)
+	}
+	data, _ := os.ReadFile(html)
+	if string(data) != "<p>1.2.3\r\n</p>\r\n<p>1.2.3\r\n</p>\r\n" {
+		t.Errorf("Got %q", data)
+	}
+	if info, _ := os.Stat(html); runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("Permissions changed to %v", info.Mode().Perm())
+	}
+	if data, _ = os.ReadFile(bin); string(data) != "0.0.0\x00" {
+		t.Error("A binary file was changed")
+	}
+
+	if _, err = runCommand("replace", "--regex", `v(?P<num>\d+) = '(\w)'`, "${num}_$2 = '${GOFILE_PRICE}'", js); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ = os.ReadFile(js); string(data) != "const 1_a = '$5';\nconst 22_b = '$5';\n" {
+		t.Errorf("Got %q", data)
+	}
+
+	// only ${NAME} is expanded, so other dollar signs are literal
+	if _, err = runCommand("replace", "const", "$5.00 $$ $${GOFILE_PRICE} ${GOFILE_PRICE}", js); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ = os.ReadFile(js); string(data) != "$5.00 $ ${GOFILE_PRICE} $5 1_a = '$5';\n$5.00 $ ${GOFILE_PRICE} $5 22_b = '$5';\n" {
+		t.Errorf("Got %q", data)
+	}
+	if _, err = runCommand("replace", "1_a", "${GOFILE_UNSET}", js); err == nil || !strings.Contains(err.Error(), "GOFILE_UNSET") {
+		t.Errorf("Expected an error for an unset variable, got %v", err)
+	}
+	if data, _ = os.ReadFile(js); !strings.Contains(string(data), "1_a") {
+		t.Error("A file was changed with an unset variable")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("Temporary files were left behind: %v", entries)
+	}
+}
+
+func TestReplaceCheck(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(f, []byte("const url = \"http://localhost\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCommand("replace", "--check", "localhost", "example.com", dir)
+	if ExitCode(err) != exitCheckFailed || !strings.Contains(out, "1 replacements would be made") {
+		t.Errorf("Expected a failed check, got %v, %q", err, out)
+	}
+	if data, _ := os.ReadFile(f); !strings.Contains(string(data), "localhost") {
+		t.Error("The file was changed")
+	}
+	if _, err = runCommand("replace", "--check", "nothing", "example.com", dir); err != nil {
+		t.Error(err)
+	}
+	if _, err = runCommand("replace", "--regex", "--literal", "a", "b", dir); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err = runCommand("replace", "--regex", "a(", "b", dir); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -5,10 +5,11 @@
 package cmd
 
 import (
-	"io/fs"
-	"log"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/goradd/gofile/pkg/sys"
 	"github.com/spf13/cobra"
@@ -16,11 +17,29 @@ import (
 
 var excludes []string
 var exclude string
+var includes []string
+var include string
+var matcher *sys.Matcher
 var modules map[string]string
 var files []string
 var copyOverwrite bool
 var copyOverwriteIfNewer bool
 var verbose bool
+var dryRun bool
+var jsonOutput bool
+var noProgress bool
+var failOnEmpty bool
+var respectIgnore bool
+var fileType string
+var minSize string
+var maxSize string
+var olderThan string
+var newerThan string
+var maxDepth int
+var hidden string
+var from string
+var fromNul bool
+var fromList []string
 var deleteAfterZip bool
 var gzipCompressionLevel int
 var brotliCompressionLevel int
@@ -29,6 +48,7 @@ var brotliCompressionLevel int
 func MakeRootCommand() (*cobra.Command, error) {
 	var err error
 
+	events = &emitter{}
 	modules, err = sys.ModulePaths()
 	if err != nil {
 		return nil, err
@@ -43,28 +63,43 @@ use standard GLOB identifiers (like * to match any string). If an identifier sta
 identifier (e.g. github.com/repo/proj), gofile will look for that file or directory in the module
 specified. Environment variables can be specified with $NAME or ${NAME}. Separate paths with
 forward slash to be cross-platform compatible.`,
-		PersistentPreRun: processExclude,
+		PersistentPreRunE: persistentPreRunE,
 	}
 
-	rootCmd.PersistentFlags().StringVarP(&exclude, "exclude", "x", "", "list of pattern match expressions, separated by semicolons or colons, that when matched, will be excluded from the list of files to process. The pattern match is the same as file GLOB pattern matching.")
+	rootCmd.PersistentFlags().StringVarP(&exclude, "exclude", "x", "", "list of pattern match expressions, separated by semicolons or colons, that when matched, will be excluded from the list of files to process. The pattern match is the same as file GLOB pattern matching. Patterns with a slash are matched against the path relative to the directory being processed, a leading slash anchors the pattern to that directory, and a trailing slash matches only directories. A pattern that starts with ! brings back what an earlier pattern excluded. Braces like *.{js,css} are expanded, and a pattern that starts with re: is a regular expression that continues to the next semicolon.")
+	rootCmd.PersistentFlags().StringVarP(&include, "include", "i", "", "list of pattern match expressions, separated by semicolons or colons. If given, only files that match will be processed. Patterns are matched the same way as exclude patterns.")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "N", false, "print the actions that would be performed, without changing anything on disk")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "write a json object to stdout for each action, followed by a summary object")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "do not show a progress bar on the terminal")
+	rootCmd.PersistentFlags().BoolVar(&respectIgnore, "respect-ignore", false, "skip the files listed in .gitignore and .gofileignore files, and the ignore files themselves, when processing directories")
+	rootCmd.PersistentFlags().BoolVar(&failOnEmpty, "fail-on-empty", false, "report an error if a glob pattern does not match any files")
+	rootCmd.PersistentFlags().StringVar(&fileType, "type", "", "only process items of the given types: f for files, d for directories, l for symbolic links. Types can be combined, as in fl.")
+	rootCmd.PersistentFlags().StringVar(&minSize, "min-size", "", "only process files at least this big, like 1500, 10k or 2M")
+	rootCmd.PersistentFlags().StringVar(&maxSize, "max-size", "", "only process files at most this big, like 1500, 10k or 2M")
+	rootCmd.PersistentFlags().StringVar(&olderThan, "older-than", "", "only process items modified before the given duration ago, like 36h or 7d, or before the modification time of the given file")
+	rootCmd.PersistentFlags().StringVar(&newerThan, "newer-than", "", "only process items modified within the given duration, like 36h or 7d, or after the modification time of the given file")
+	rootCmd.PersistentFlags().StringVar(&hidden, "hidden", os.Getenv("GOFILE_HIDDEN"), "include or exclude hidden files and directories, whose names start with a dot or that have the Windows hidden attribute. The default is the value of the GOFILE_HIDDEN environment variable, or include if it is not set.")
+	rootCmd.PersistentFlags().StringVar(&from, "from", "", "read more files to process from the given file, or from stdin if -. Put each file on its own line, or separate them with NUL characters and use -0.")
+	rootCmd.PersistentFlags().BoolVarP(&fromNul, "null", "0", false, "the files read with --from are separated by NUL characters, as written by git ls-files -z or find -print0")
+	rootCmd.PersistentFlags().IntVar(&maxDepth, "max-depth", 0, "only process the items this many levels deep in the directories being processed. 1 processes just the items directly inside them.")
 
 	var cmdRemove = &cobra.Command{
-		Use:    "remove [files to remove]",
-		Short:  "Deletes the given files.",
-		Long:   `Deletes the listed files and directories permanently. Use with care.`,
-		Args:   cobra.MinimumNArgs(1),
-		PreRun: processFileListArgs,
-		RunE:   removeFiles,
+		Use:     "remove [files to remove]",
+		Short:   "Deletes the given files.",
+		Long:    `Deletes the listed files and directories permanently. Use with care.`,
+		Args:    fileArgs(1),
+		PreRunE: processFileListArgs,
+		RunE:    withEvents(removeFiles),
 	}
 
 	var cmdGenerate = &cobra.Command{
-		Use:    "generate [files to hand off to go generate]",
-		Short:  "go generate the given files.",
-		Long:   `Passes the given files to go generate.`,
-		Args:   cobra.MinimumNArgs(1),
-		PreRun: processFileListArgs,
-		RunE:   generateFiles,
+		Use:     "generate [files to hand off to go generate]",
+		Short:   "go generate the given files.",
+		Long:    `Passes the given files to go generate.`,
+		Args:    fileArgs(1),
+		PreRunE: processFileListArgs,
+		RunE:    withEvents(generateFiles),
 	}
 
 	var cmdCopy = &cobra.Command{
@@ -73,39 +108,39 @@ forward slash to be cross-platform compatible.`,
 		Long: `Copies the files or directories to the given location. If you are copying one
 file, the destination can be a file name that does not exist, but whose parent exists. If 
 copying more than one file, the destination must be a directory that exists.`,
-		Args: cobra.MinimumNArgs(2),
-		RunE: copyFiles,
+		Args: fileArgs(2),
+		RunE: withEvents(copyFiles),
 	}
 	cmdCopy.Flags().BoolVarP(&copyOverwrite, "overwrite", "o", false, "Files will overwrite previous files when copying.")
 	cmdCopy.Flags().BoolVarP(&copyOverwriteIfNewer, "newer", "n", false, "Files will overwrite previous files when copying only if the new file is newer than the old.")
 
 	var cmdMkDir = &cobra.Command{
-		Use:    "mkdir [directory to create]",
-		Short:  "Create the given directory.",
-		Long:   `Create the given directory.`,
-		Args:   cobra.MinimumNArgs(1),
-		PreRun: processFileListArgs,
-		RunE:   mkDir,
+		Use:     "mkdir [directory to create]",
+		Short:   "Create the given directory.",
+		Long:    `Create the given directory.`,
+		Args:    fileArgs(1),
+		PreRunE: processFileListArgs,
+		RunE:    withEvents(mkDir),
 	}
 
 	var cmdGZip = &cobra.Command{
-		Use:    "gzip [files or directories to zip]",
-		Short:  "GZip the given files or directories.",
-		Long:   `GZips the given files, or all the files in the specified directories, placing zipped files alongside the given files, with .gz suffixes. Uses the maximum compression algorithm.`,
-		Args:   cobra.MinimumNArgs(1),
-		PreRun: processExpandedFileListArgs,
-		RunE:   gzip,
+		Use:     "gzip [files or directories to zip]",
+		Short:   "GZip the given files or directories.",
+		Long:    `GZips the given files, or all the files in the specified directories, placing zipped files alongside the given files, with .gz suffixes. Uses the maximum compression algorithm.`,
+		Args:    fileArgs(1),
+		PreRunE: processExpandedFileListArgs,
+		RunE:    withEvents(gzip),
 	}
 	cmdGZip.Flags().BoolVarP(&deleteAfterZip, "delete", "d", false, "Compressed source files will be deleted, leaving only the compressed version.")
 	cmdGZip.Flags().IntVarP(&gzipCompressionLevel, "quality", "q", 9, "The compression level to use. Higher numbers offer higher compression and slower compression speed, but have negligible effect on decompression speed.")
 
 	var cmdBrotli = &cobra.Command{
-		Use:    "brotli [files or directories to compress]",
-		Short:  "Brotli compress the given files or directories.",
-		Long:   `Compresses the given files with the Brotli method, or all the files in the specified directories, placing compressed files alongside the given files, with .br suffixes.`,
-		Args:   cobra.MinimumNArgs(1),
-		PreRun: processExpandedFileListArgs,
-		RunE:   brotli,
+		Use:     "brotli [files or directories to compress]",
+		Short:   "Brotli compress the given files or directories.",
+		Long:    `Compresses the given files with the Brotli method, or all the files in the specified directories, placing compressed files alongside the given files, with .br suffixes.`,
+		Args:    fileArgs(1),
+		PreRunE: processExpandedFileListArgs,
+		RunE:    withEvents(brotli),
 	}
 	cmdBrotli.Flags().BoolVarP(&deleteAfterZip, "delete", "d", false, "Compressed source files will be deleted, leaving only the compressed version.")
 	cmdBrotli.Flags().IntVarP(&brotliCompressionLevel, "quality", "q", 11, "The compression level to use. Higher numbers offer higher compression and slower compression speed, and have negligible effect on decompression speed.")
@@ -115,86 +150,305 @@ copying more than one file, the destination must be a directory that exists.`,
 		Short: "Converts a module relative path to its absolute path.",
 		Long:  `Converts a module relative path to its absolute path and sends it to stdout.`,
 		Args:  cobra.ExactArgs(1),
-		RunE:  outPath,
+		RunE:  withEvents(outPath),
 	}
 
-	rootCmd.AddCommand(cmdRemove, cmdGenerate, cmdCopy, cmdMkDir, cmdGZip, cmdBrotli, cmdPath)
+	var cmdList = &cobra.Command{
+		Use:   "list [files or directories to list]",
+		Short: "Lists the files that the other commands would process.",
+		Long: `Prints the files that the given arguments describe, after environment variables, module paths, glob patterns,
+excludes, includes and the filter flags are processed. Without --recursive, the list is what copy would be given.
+Directories are listed whatever the filter flags select, since copy applies them to the files inside. With --recursive,
+directories are replaced by the files inside them, which is what gzip and brotli would process.`,
+		Args:    fileArgs(1),
+		PreRunE: processListArgs,
+		RunE:    withEvents(listFiles),
+	}
+	cmdList.Flags().BoolVarP(&listRecursive, "recursive", "r", false, "list the files inside directories, instead of the directories")
+	cmdList.Flags().StringVar(&listFormat, "format", listNative, "how to print each file: native for paths using the platform's separator, slash for paths using forward slashes, or json for an object with the path, size and modification time on each line")
+	cmdList.Flags().StringVar(&listRelativeTo, "relative-to", "", "print paths relative to the given directory, which can start with a module path")
+
+	var cmdGrep = &cobra.Command{
+		Use:   "grep [regular expression] [files or directories to search]",
+		Short: "Search the given files for a regular expression.",
+		Long: `Searches the given files, or all the files in the specified directories, for lines that match the
+regular expression, and prints each match with its file and line number. Binary files are skipped.
+The exit code is 0 if the search succeeds, 3 if --fail-if-found or --fail-if-missing fail, and 1 for any
+other error, like an invalid expression or a file that cannot be searched.`,
+		Args:    fileArgs(2),
+		PreRunE: processGrepArgs,
+		RunE:    withEvents(grep),
+	}
+	cmdGrep.Flags().BoolVar(&grepIgnoreCase, "ignore-case", false, "ignore case when matching")
+	cmdGrep.Flags().BoolVarP(&grepFilesOnly, "files-with-matches", "l", false, "only print the names of the files that have a match")
+	cmdGrep.Flags().BoolVarP(&grepCount, "count", "c", false, "print the number of matching lines in each file")
+	cmdGrep.Flags().BoolVar(&grepFailIfFound, "fail-if-found", false, "exit with code 3 if there is a match")
+	cmdGrep.Flags().BoolVar(&grepFailIfMissing, "fail-if-missing", false, "exit with code 3 if there is no match")
+
+	var cmdReplace = &cobra.Command{
+		Use:   "replace [text to find] [replacement] [files or directories to change]",
+		Short: "Replace text in the given files.",
+		Long: `Replaces the text in the given files, or all the files in the specified directories, and reports the number
+of replacements in each file that changed. Files are rewritten atomically, keeping their permissions and line endings.
+Binary files are skipped. Environment variables written as ${NAME} in the replacement are replaced by their values,
+and it is an error if one is not set. Use $$ for a $ that is followed by {. Any other $ is left alone, except with
+--regex. With --regex, the text to find is a regular expression, $1 or ${1} in the replacement is replaced by the text
+its first group matched, ${name} by the text of the group with that name, and $$ must be used for every literal $.`,
+		Args:    fileArgs(3),
+		PreRunE: processReplaceArgs,
+		RunE:    withEvents(replaceInFiles),
+	}
+	cmdReplace.Flags().BoolVar(&replaceRegex, "regex", false, "the text to find is a regular expression")
+	cmdReplace.Flags().BoolVar(&replaceLiteral, "literal", false, "the text to find is matched exactly. This is the default.")
+	cmdReplace.Flags().BoolVar(&replaceCheck, "check", false, "do not change any files, but exit with code 3 if a file would change")
+
+	var cmdTemplate = &cobra.Command{
+		Use:   "template [template file or directory] [destination file or directory]",
+		Short: "Render Go templates.",
+		Long: `Renders a file written in Go's text/template language to the destination. Templates whose output has an .html
+or .htm extension are rendered with html/template. If the source is a directory, every .tmpl file in it is rendered into
+the same place in the destination directory, without the .tmpl extension. The data comes from --data and --set.`,
+		Args: cobra.ExactArgs(2),
+		RunE: withEvents(renderTemplates),
+	}
+	cmdTemplate.Flags().StringVar(&templateData, "data", "", "a .json, .yaml or .yml file with the data to render")
+	cmdTemplate.Flags().StringArrayVar(&templateSet, "set", nil, "set a data value, as in --set version=1.2. Use dots to set a value inside a map, as in --set site.url=https://example.com")
+
+	rootCmd.AddCommand(cmdRemove, cmdGenerate, cmdCopy, cmdMkDir, cmdGZip, cmdBrotli, cmdPath, cmdList, cmdGrep, cmdReplace, cmdTemplate)
 
 	return rootCmd, nil
 }
 
-func processExclude(_ *cobra.Command, _ []string) {
-	exclude = os.ExpandEnv(exclude)
-	excludes = sys.SplitList(exclude)
+func persistentPreRunE(cmd *cobra.Command, args []string) error {
+	events = newEmitter(cmd)
+	if err := processPatterns(cmd, args); err != nil {
+		return err
+	}
+	if err := processFilter(cmd, args); err != nil {
+		return err
+	}
+	if err := processHidden(cmd, args); err != nil {
+		return err
+	}
+	return processFrom(cmd, args)
 }
 
-// processFileListArgs accepts the group of arguments that would represent files, directories
-// etc., processes them, removes excluded files, and sets the files global to this list
-// non-existent names are left intact so that we can create them.
-func processFileListArgs(_ *cobra.Command, args []string) {
-	files2 := sys.ModuleExpandFileList(args, modules)
+// fileArgs returns a cobra.PositionalArgs that requires at least n arguments, one of which can be replaced
+// by a list of files given with --from.
+func fileArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if from != "" {
+			return cobra.MinimumNArgs(n-1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(n)(cmd, args)
+	}
+}
 
-	if excludes == nil {
-		files = files2
+// processFrom reads the list of files given with --from into the fromList global.
+func processFrom(cmd *cobra.Command, _ []string) (err error) {
+	fromList = nil
+	if from == "" {
+		return nil
+	}
+	if from == "-" {
+		fromList, err = sys.ReadFileList(cmd.InOrStdin(), fromNul)
+		return
+	}
+	f, err := os.Open(processFileArg(from))
+	if err != nil {
 		return
 	}
+	defer f.Close()
+	fromList, err = sys.ReadFileList(f, fromNul)
+	return
+}
 
-	files = nil
-Files2:
-	for _, f := range files2 {
-		for _, e := range excludes {
-			m, _ := filepath.Match(e, filepath.Base(f))
-			if m {
-				continue Files2
-			}
+func processPatterns(_ *cobra.Command, _ []string) error {
+	exclude = os.ExpandEnv(exclude)
+	excludes = sys.SplitPatterns(exclude)
+	if err := sys.ValidatePatterns(excludes); err != nil {
+		return fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	include = os.ExpandEnv(include)
+	includes = sys.SplitPatterns(include)
+	if err := sys.ValidatePatterns(includes); err != nil {
+		return fmt.Errorf("invalid include pattern: %w", err)
+	}
+	matcher = sys.NewMatcher(includes, excludes)
+	if respectIgnore {
+		matcher.IgnoreFiles = []string{sys.GitIgnore, sys.GofileIgnore}
+	}
+	return nil
+}
+
+// processFilter sets the filter of the matcher from the flags that select files by type, size, age and depth.
+func processFilter(_ *cobra.Command, _ []string) (err error) {
+	f := &sys.Filter{Types: fileType, MaxDepth: maxDepth}
+	if minSize != "" {
+		if f.MinSize, err = sys.ParseSize(minSize); err != nil {
+			return
+		}
+	}
+	if maxSize != "" {
+		if f.MaxSize, err = sys.ParseSize(maxSize); err != nil {
+			return
 		}
-		files = append(files, f)
 	}
+	if olderThan != "" {
+		if f.OlderThan, err = parseAge(olderThan); err != nil {
+			return
+		}
+	}
+	if newerThan != "" {
+		if f.NewerThan, err = parseAge(newerThan); err != nil {
+			return
+		}
+	}
+	if err = f.Validate(); err != nil {
+		return
+	}
+	if *f != (sys.Filter{}) {
+		matcher.Filter = f
+	}
+	return nil
 }
 
-// processExistingFileListArgs accepts the group of arguments that would represent files, directories
-// etc., expands the list based on the current modules, expands directories to the list of files in those
-// directories, removes excluded files, and sets the files global to this list.
-// non-existent names are removed
-func processExpandedFileListArgs(_ *cobra.Command, args []string) {
-	files2 := sys.ModuleExpandFileList(args, modules)
+// processHidden sets the hidden file policy of the matcher from the --hidden flag.
+func processHidden(_ *cobra.Command, _ []string) error {
+	switch hidden {
+	case "", "include":
+		matcher.Hidden = sys.HiddenInclude
+	case "exclude":
+		matcher.Hidden = sys.HiddenExclude
+	default:
+		return fmt.Errorf("invalid hidden file policy %q, use include or exclude", hidden)
+	}
+	return nil
+}
 
-	files = nil
+// parseAge returns the time that is the given duration ago, or the modification time of the given file.
+func parseAge(s string) (time.Time, error) {
+	if d, err := sys.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	info, err := os.Stat(processFileArg(s))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is not a duration or a file", s)
+	}
+	return info.ModTime(), nil
+}
 
-	for _, f := range files2 {
-		if sys.IsDir(f) {
-			_ = filepath.WalkDir(f, func(path string, d fs.DirEntry, err error) error {
-				if err == nil {
-					if d.IsDir() {
-						if isExcluded(path) {
-							return filepath.SkipDir
-						}
-					} else {
-						if !isExcluded(path) {
-							files = append(files, path)
-						}
-					}
-				}
-				return nil
-			})
-		} else {
-			if isExcluded(f) {
-				continue
-			}
+// processFileListArgs accepts the group of arguments that would represent files, directories
+// etc., processes them, removes excluded files, hidden files that are excluded, and files that are not included or
+// not selected by the filter flags, and sets the files global to this list
+// non-existent names are left intact so that we can create them.
+func processFileListArgs(_ *cobra.Command, args []string) error {
+	return processFileList(args, false)
+}
+
+// processFileList implements processFileListArgs. If keepDirs is true, directories are not compared against the
+// filter flags, since the command applies them to the files inside.
+func processFileList(args []string, keepDirs bool) error {
+	list, err := expandFileListArgs(args)
+	if err != nil {
+		return err
+	}
+	files = nil
+	for _, f := range list {
+		isDir := sys.IsDir(f)
+		if matcher.MatchRoot(f, isDir) && (keepDirs && isDir || matcher.Filter.MatchFile(f)) && !matcher.SkipHidden(f) {
 			files = append(files, f)
 		}
 	}
+	return nil
+}
+
+// processExpandedFileListArgs accepts the group of arguments that would represent files, directories
+// etc., expands the list based on the current modules, expands directories to the list of files in those
+// directories, removes excluded files and files not selected by the filter flags, and sets the files global to this list.
+// A directory that cannot be read is an error, so that a command does not report success after processing only some
+// of the files.
+func processExpandedFileListArgs(cmd *cobra.Command, args []string) (err error) {
+	list, err := expandFileListArgs(args)
+	if err != nil {
+		return
+	}
+	if files, err = sys.ExpandDirs(list, matcher); err != nil {
+		// the arguments are valid, so a directory that cannot be read should not print the usage
+		cmd.SilenceUsage = true
+	}
+	return
 }
 
-// isExcluded returns true if the given file matches one of the exclusion strings
-func isExcluded(file string) bool {
-	for _, e := range excludes {
-		m, _ := filepath.Match(e, filepath.Base(file))
-		if m {
-			return true
+// expandFileListArgs expands the module paths and glob patterns in args, followed by the files read with --from.
+// Patterns that match nothing are reported in verbose mode, or are an error if --fail-on-empty was given.
+// The files read with --from are real file names, as written by git ls-files or find, so only module paths are
+// replaced in them.
+func expandFileListArgs(args []string) ([]string, error) {
+	list, unmatched, err := sys.ModuleExpandFileListEx(args, modules)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range unmatched {
+		if failOnEmpty {
+			return nil, &sys.PatternError{Pattern: u, Err: sys.ErrNoMatch}
+		}
+		events.logf("Pattern %s did not match any files\n", u)
+	}
+	if len(fromList) == 0 {
+		return list, nil
+	}
+	seen := make(map[string]bool)
+	for _, f := range list {
+		seen[f] = true
+	}
+	for _, f := range fromList {
+		f, _ = sys.GetModulePath(f, modules)
+		if !seen[f] {
+			seen[f] = true
+			list = append(list, f)
 		}
 	}
-	return false
+	return list, nil
+}
+
+// Exit codes returned by ExitCode. Checks, like grep --fail-if-found, exit with exitCheckFailed when they fail, so
+// that build scripts can tell a failed check from a usage error or a file that could not be processed.
+const (
+	exitFailed      = 1
+	exitCheckFailed = 3
+)
+
+// exitError is an error that makes gofile exit with a code other than exitFailed.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode returns the exit code that gofile should return for an error returned by a command.
+func ExitCode(err error) int {
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitFailed
+}
+
+// absPath returns the absolute version of the given path for reporting purposes.
+// If the path cannot be made absolute, it is returned unchanged.
+func absPath(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		return p
+	}
+	return path
 }
 
 func processFileArg(arg string) string {
@@ -202,8 +456,8 @@ func processFileArg(arg string) string {
 	arg2, _ := sys.GetModulePath(arg, modules)
 	arg2 = filepath.FromSlash(arg2)
 
-	if verbose && arg2 != arg {
-		log.Printf("Module path " + arg + " found at " + arg2 + "\n")
+	if arg2 != arg {
+		events.logf("Module path %s found at %s\n", arg, arg2)
 	}
 	return arg2
 }
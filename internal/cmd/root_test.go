@@ -5,11 +5,46 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/goradd/gofile/pkg/sys"
 )
 
+// runCommand runs gofile with the given arguments, and returns what it wrote to stdout.
+func runCommand(args ...string) (string, error) {
+	cmd, _ := MakeRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// writeTree writes the files, keyed by their slash separated paths relative to root, creating the directories
+// they are in.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func TestModules(t *testing.T) {
 	dir := filepath.Join(os.TempDir(), "gofileTest")
 
@@ -54,3 +89,268 @@ func TestModules(t *testing.T) {
 	}
 
 }
+
+func TestDryRun(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "gofileDryRunTest")
+	_ = os.RemoveAll(dir)
+	if err := os.Mkdir(dir, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	cmd, _ := MakeRootCommand()
+	cmd.SetOut(&buf)
+
+	cmd.SetArgs([]string{"copy", "-N", "testdata/copytest/*", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Error(err)
+	}
+	if items, _ := os.ReadDir(dir); len(items) != 0 {
+		t.Error("Dry run copied files")
+	}
+	if !strings.Contains(buf.String(), "Would copy") ||
+		!strings.Contains(buf.String(), filepath.Join(dir, "a", "t1.txt")) {
+		t.Error("Dry run did not report the copy: " + buf.String())
+	}
+
+	// compressed files are skipped, as they are in a real run
+	_ = os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "a.txt.gz"), []byte("a"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "a.txt.br"), []byte("a"), 0o644)
+	for c, ext := range map[string]string{"gzip": ".gz", "brotli": ".br"} {
+		buf.Reset()
+		cmd.SetArgs([]string{c, "-N", filepath.Join(dir, "a.txt*")})
+		if err := cmd.Execute(); err != nil {
+			t.Error(err)
+		}
+		if strings.Contains(buf.String(), ext+" to") || !strings.Contains(buf.String(), filepath.Join(dir, "a.txt")+" to") {
+			t.Errorf("Dry run of %s reported %s", c, buf.String())
+		}
+	}
+
+	buf.Reset()
+	cmd.SetArgs([]string{"remove", "-N", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Error("Dry run removed the directory")
+	}
+	if buf.String() != "Would remove "+dir+"\n" {
+		t.Error("Dry run did not report the removal: " + buf.String())
+	}
+}
+
+func TestJsonOutput(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "gofileJsonTest")
+	_ = os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	cmd, _ := MakeRootCommand()
+	cmd.SetOut(&buf)
+
+	cmd.SetArgs([]string{"mkdir", "--json", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Error(err)
+	}
+	buf.Reset()
+	cmd.SetArgs([]string{"copy", "--json", "-x", "*.abc", "testdata/copytest/c", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	dec := json.NewDecoder(&buf)
+	var list []jsonEvent
+	var summary jsonSummary
+	for {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			break
+		}
+		b, _ := json.Marshal(m)
+		if m["op"] == opSummary {
+			_ = json.Unmarshal(b, &summary)
+		} else {
+			var e jsonEvent
+			_ = json.Unmarshal(b, &e)
+			list = append(list, e)
+		}
+	}
+
+	var copied, skipped int
+	for _, e := range list {
+		if e.Op == "copy" && e.Skipped == "" {
+			copied++
+			if e.Bytes == 0 {
+				t.Error("Bytes not reported")
+			}
+		}
+		if e.Skipped == "excluded" {
+			skipped++
+		}
+	}
+	if copied != 2 || skipped != 1 {
+		t.Errorf("Wrong events reported: %v", list)
+	}
+	if summary.Command != "copy" || summary.Skipped != 1 || summary.Actions != len(list)-1 {
+		t.Errorf("Wrong summary: %v", summary)
+	}
+	if summary.Result == nil || len(summary.Result.Copied) != 2 || len(summary.Result.DirsCreated) != 2 {
+		t.Errorf("Wrong copy result: %v", summary.Result)
+	}
+}
+
+func TestJsonPreRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"gzip", "--json", "-x", "[abc", "testdata/copytest"},
+		{"gzip", "--json"},
+		{"grep", "--json", "(", "testdata/copytest"},
+	} {
+		var buf bytes.Buffer
+		cmd, _ := MakeRootCommand()
+		cmd.SetOut(&buf)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		if err := Execute(context.Background(), cmd); err == nil {
+			t.Errorf("%v: expected an error", args)
+			continue
+		}
+		// cobra writes the usage to the output given to SetOut, which is stderr when running gofile
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) > 2 {
+			lines = lines[len(lines)-2:]
+		}
+		var e jsonEvent
+		var summary jsonSummary
+		if len(lines) != 2 || json.Unmarshal([]byte(lines[0]), &e) != nil || json.Unmarshal([]byte(lines[1]), &summary) != nil {
+			t.Errorf("%v: got %q", args, buf.String())
+			continue
+		}
+		if e.Error == "" || summary.Op != opSummary || summary.Command != args[0] || summary.Errors != 1 {
+			t.Errorf("%v: got %v and %v", args, e, summary)
+		}
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	cmd, _ := MakeRootCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	cmd.SetArgs([]string{"gzip", "-x", "[abc", "testdata/copytest"})
+	if err := cmd.Execute(); !errors.Is(err, filepath.ErrBadPattern) || !strings.Contains(err.Error(), "[abc") {
+		t.Errorf("Expected a bad exclude pattern error, got %v", err)
+	}
+
+	cmd.SetArgs([]string{"remove", "testdata/[abc*"})
+	if err := cmd.Execute(); !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("Expected a bad pattern error, got %v", err)
+	}
+
+	cmd.SetArgs([]string{"gzip", "-x", "", "--fail-on-empty", "testdata/*.none"})
+	if err := cmd.Execute(); !errors.Is(err, sys.ErrNoMatch) || !strings.Contains(err.Error(), "*.none") {
+		t.Errorf("Expected a no match error, got %v", err)
+	}
+}
+
+func TestFilterFlags(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{"old.log": 10, "new.log": 10, "big.log": 5000} {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.log"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	week := time.Now().Add(-8 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "old.log"), week, week); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, _ := MakeRootCommand()
+	cmd.SetArgs([]string{"gzip", "--min-size", "1k", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	cmd, _ = MakeRootCommand()
+	cmd.SetArgs([]string{"remove", "--type", "f", "--older-than", "7d", filepath.Join(dir, "*.log")})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for f, want := range map[string]bool{
+		"old.log":    false,
+		"new.log":    true,
+		"big.log":    true,
+		"big.log.gz": true,
+		"new.log.gz": false,
+		"sub.log":    true,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, f)); (err == nil) != want {
+			t.Errorf("%s exists: %v, want %v", f, err == nil, want)
+		}
+	}
+
+	cmd, _ = MakeRootCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"remove", "--newer-than", "nofile", dir})
+	if err := cmd.Execute(); err == nil {
+		t.Error("Expected an invalid age error")
+	}
+}
+
+func TestFromFlag(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.js": "test", "b.js": "test", "c.js": "test"})
+
+	cmd, _ := MakeRootCommand()
+	cmd.SetIn(strings.NewReader(filepath.Join(dir, "a.js") + "\x00" + filepath.Join(dir, "b.js") + "\x00"))
+	cmd.SetArgs([]string{"gzip", "--from", "-", "-0"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for f, want := range map[string]bool{"a.js.gz": true, "b.js.gz": true, "c.js.gz": false} {
+		if _, err := os.Stat(filepath.Join(dir, f)); (err == nil) != want {
+			t.Errorf("%s exists: %v, want %v", f, err == nil, want)
+		}
+	}
+
+	// the entries are file names, so glob characters, braces and dollar signs are not expanded
+	names := []string{"[ac].js", "{a,b}.js", "price$x.js"}
+	for _, f := range names {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("test"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := filepath.Join(dir, "files.txt")
+	var b strings.Builder
+	for _, f := range append(names, "c.js") {
+		b.WriteString(filepath.Join(dir, f) + "\r\n")
+	}
+	if err := os.WriteFile(list, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "dest")
+	if err := os.Mkdir(dest, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cmd, _ = MakeRootCommand()
+	cmd.SetArgs([]string{"copy", "--from", list, dest})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	if expected := []string{"[ac].js", "c.js", "price$x.js", "{a,b}.js"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v", got)
+	}
+}
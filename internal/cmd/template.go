@@ -0,0 +1,189 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goradd/gofile/pkg/sys"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var templateData string
+var templateSet []string
+
+// templateExt is the extension of the files in a directory that are rendered, and is removed from the names of
+// the rendered files.
+const templateExt = ".tmpl"
+
+// executor is the part of a text or html template that renders it.
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+func renderTemplates(cmd *cobra.Command, args []string) error {
+	src := processFileArg(args[0])
+	dest := processFileArg(args[1])
+
+	data, err := templateValues()
+	if err != nil {
+		return err
+	}
+
+	if !sys.IsDir(src) {
+		if sys.IsDir(dest) {
+			dest = filepath.Join(dest, strings.TrimSuffix(filepath.Base(src), templateExt))
+		}
+		return renderTemplate(src, dest, data)
+	}
+
+	return sys.Walk(src, matcher, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = cmd.Context().Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, templateExt) {
+			return nil
+		}
+		rel, _ := filepath.Rel(src, p)
+		out := filepath.Join(dest, strings.TrimSuffix(rel, templateExt))
+		if !dryRun {
+			if err = os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+				return err
+			}
+		}
+		return renderTemplate(p, out, data)
+	})
+}
+
+// renderTemplate renders the template in the src file to the dest file. The file is rendered as an html/template if
+// dest has an html extension, and as a text/template otherwise.
+func renderTemplate(src, dest string, data any) error {
+	start := time.Now()
+	text, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	var t executor
+	switch strings.ToLower(filepath.Ext(dest)) {
+	case ".html", ".htm":
+		t, err = htmltemplate.New(filepath.Base(src)).Funcs(htmltemplate.FuncMap(templateFuncs())).Parse(string(text))
+	default:
+		t, err = template.New(filepath.Base(src)).Funcs(templateFuncs()).Parse(string(text))
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing template %s: %w", src, err)
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error rendering template %s: %w", src, err)
+	}
+
+	if dryRun {
+		events.Emit(sys.Event{Op: opTemplate, Source: absPath(src), Destination: absPath(dest)})
+		return nil
+	}
+	perm := fs.FileMode(0o644)
+	if info, err := os.Stat(src); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err = sys.WriteFileAtomic(dest, buf.Bytes(), perm); err != nil {
+		return fmt.Errorf("error writing file %s: %w", dest, err)
+	}
+	events.Emit(sys.Event{Op: opTemplate, Source: src, Destination: dest, Bytes: int64(buf.Len()), Duration: time.Since(start)})
+	return nil
+}
+
+// templateFuncs returns the functions that templates can call. Paths given to the functions can start with a
+// module path.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"env": os.Getenv,
+		"modulePath": func(p string) (string, error) {
+			return sys.GetModulePath(p, modules)
+		},
+		"importPath": func(p string) (string, error) {
+			return sys.ImportPath(processFileArg(p))
+		},
+		"modules": func() map[string]string {
+			return modules
+		},
+		"goos": func() string {
+			return runtime.GOOS
+		},
+		"goarch": func() string {
+			return runtime.GOARCH
+		},
+		"readFile": func(p string) (string, error) {
+			b, err := os.ReadFile(processFileArg(p))
+			return string(b), err
+		},
+	}
+}
+
+// templateValues returns the data given to the templates, which is read from the --data file, with the --set values
+// added to it.
+func templateValues() (map[string]any, error) {
+	values := make(map[string]any)
+	if templateData != "" {
+		f := processFileArg(templateData)
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(filepath.Ext(f)) {
+		case ".json":
+			err = json.Unmarshal(b, &values)
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(b, &values)
+		default:
+			return nil, fmt.Errorf("the data file %s must have a .json, .yaml or .yml extension", templateData)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading data file %s: %w", templateData, err)
+		}
+	}
+	for _, s := range templateSet {
+		k, v, ok := strings.Cut(s, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid value %q, use --set key=value", s)
+		}
+		if err := setValue(values, strings.Split(k, "."), v); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// setValue sets the value at the path of keys in values, creating maps as needed.
+func setValue(values map[string]any, keys []string, v string) error {
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := values[k].(map[string]any)
+		if !ok {
+			if _, exists := values[k]; exists {
+				return fmt.Errorf("cannot set %s, since %s is not a map", strings.Join(keys, "."), k)
+			}
+			next = make(map[string]any)
+			values[k] = next
+		}
+		values = next
+	}
+	values[keys[len(keys)-1]] = v
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "config.go.tmpl")
+	data := filepath.Join(dir, "data.yaml")
+	text := `package {{.pkg}}
+// {{.site.name}} {{.site.version}} {{range .site.tags}}{{.}} {{end}}
+const os = "{{goos}}/{{goarch}}"
+const user = "{{env "GOFILE_USER"}}"
+const note = "{{readFile .note}}"
+`
+	if err := os.WriteFile(src, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(data, []byte("pkg: config\nsite:\n  name: 'Site' # the name\n  version: 1\n  tags: [a, b]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "note.txt"), []byte("hi"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOFILE_USER", "sam")
+
+	if _, err := runCommand("template", "--data", data, "--set", "site.version=2", "--set", "note="+filepath.Join(dir, "note.txt"), src, dir); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "config.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "package config\n// Site 2 a b \nconst os = \"" + runtime.GOOS + "/" + runtime.GOARCH + "\"\nconst user = \"sam\"\nconst note = \"hi\"\n"
+	if string(out) != expected {
+		t.Errorf("Got %q", out)
+	}
+
+	if _, err = runCommand("template", "--set", "pkg.name=x", "--data", data, src, dir); err == nil {
+		t.Error("Expected an error setting a value inside a string")
+	}
+	if _, err = runCommand("template", "--set", "pkg", src, dir); err == nil {
+		t.Error("Expected an error for a --set without a value")
+	}
+}
+
+func TestTemplateDirectory(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dest := filepath.Join(dir, "dest")
+	data := filepath.Join(dir, "data.json")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "index.html.tmpl"), []byte("<p>{{.title}}</p>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "a.txt.tmpl"), []byte("{{.title}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("{{.title}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(data, []byte(`{"title": "a < b"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runCommand("template", "-N", "--data", data, src, dest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dest); err == nil {
+		t.Error("A dry run rendered files")
+	}
+
+	if _, err := runCommand("template", "--data", data, src, dest); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := os.ReadFile(filepath.Join(dest, "index.html")); string(out) != "<p>a &lt; b</p>" {
+		t.Errorf("Got %q", out)
+	}
+	if out, _ := os.ReadFile(filepath.Join(dest, "sub", "a.txt")); string(out) != "a < b" {
+		t.Errorf("Got %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "sub", "b.txt")); err == nil {
+		t.Error("A file without the .tmpl extension was rendered")
+	}
+}
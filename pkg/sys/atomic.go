@@ -0,0 +1,50 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to the named file so that readers see either the old content or the new content,
+// and never a partly written file. The data is written to a temporary file in the same directory, which is then
+// synced to disk and renamed over the named file.
+//
+// If the file exists, its permissions are kept. Otherwise, it is created with perm. If name is a symbolic link,
+// the file it points to is replaced, and the link is kept.
+func WriteFileAtomic(name string, data []byte, perm fs.FileMode) (err error) {
+	if target, err := filepath.EvalSymlinks(name); err == nil {
+		name = target
+	}
+	if info, err := os.Stat(name); err == nil {
+		perm = info.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
+		return
+	}
+	if err = f.Chmod(perm); err != nil {
+		return
+	}
+	// without a sync, a crash after the rename could leave an empty file
+	if err = f.Sync(); err != nil {
+		return
+	}
+	if err = f.Close(); err != nil {
+		return
+	}
+	return os.Rename(f.Name(), name)
+}
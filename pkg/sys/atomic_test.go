@@ -0,0 +1,59 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "a.txt")
+	if err := WriteFileAtomic(f, []byte("one"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(f, []byte("two"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(f); string(data) != "two" {
+		t.Errorf("Got %q", data)
+	}
+	if info, _ := os.Stat(f); runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("Permissions changed to %v", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Temporary files were left behind: %v", entries)
+	}
+	if err := WriteFileAtomic(filepath.Join(dir, "none", "a.txt"), nil, 0o644); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "a.txt")
+	link := filepath.Join(dir, "link.txt")
+	if err := os.WriteFile(f, []byte("one"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("a.txt", link); err != nil {
+		t.Skip("cannot create a symbolic link:", err)
+	}
+	if err := WriteFileAtomic(link, []byte("two"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("The link was replaced")
+	}
+	if data, _ := os.ReadFile(f); string(data) != "two" {
+		t.Errorf("Got %q", data)
+	}
+	if info, _ := os.Stat(f); runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("Permissions changed to %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,741 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SymlinkPolicy determines how Copy treats symbolic links found in the source.
+type SymlinkPolicy int
+
+const (
+	// SymlinkFollow copies the file or directory that the link points to.
+	SymlinkFollow SymlinkPolicy = iota
+	// SymlinkCopy creates a link in the destination with the same target as the source link.
+	SymlinkCopy
+	// SymlinkSkip does not copy links.
+	SymlinkSkip
+)
+
+// CopyProgress is passed to CopyOptions.Progress to report the progress of a copy.
+type CopyProgress struct {
+	// Source is the file being copied.
+	Source string
+	// Destination is the file being written.
+	Destination string
+	// FileBytes is the number of bytes of the current file that have been written.
+	FileBytes int64
+	// FileSize is the size of the current file.
+	FileSize int64
+	// FilesDone is the number of files that have been completely copied.
+	FilesDone int
+	// TotalBytes is the number of bytes written by the whole copy operation so far.
+	TotalBytes int64
+	// FilesFound is the number of files that the copy has found to write so far, including those already written.
+	// The files in a directory are found when the directory is read, so FilesFound grows as the copy goes deeper,
+	// and is only the total once every directory has been read.
+	FilesFound int
+	// BytesFound is the size of the files in FilesFound.
+	BytesFound int64
+}
+
+// CopiedFile describes a file written by Copy.
+type CopiedFile struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Bytes       int64  `json:"bytes"`
+}
+
+// SkippedFile describes a file that Copy did not write, and why.
+type SkippedFile struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+// CopyResult describes what a copy operation did. In a dry run, it describes what the copy would have done.
+type CopyResult struct {
+	// Copied lists the files and symbolic links that were written.
+	Copied []CopiedFile `json:"copied,omitempty"`
+	// Skipped lists the files that were not written, with the reason. See the Skip constants.
+	Skipped []SkippedFile `json:"skipped,omitempty"`
+	// DirsCreated lists the directories that were created.
+	DirsCreated []string `json:"dirsCreated,omitempty"`
+	// BytesWritten is the total size of the files written.
+	BytesWritten int64 `json:"bytesWritten"`
+}
+
+// Changed returns true if the copy wrote any files or created any directories.
+func (r CopyResult) Changed() bool {
+	return len(r.Copied) > 0 || len(r.DirsCreated) > 0
+}
+
+// CopyOptions controls the behavior of Copy.
+type CopyOptions struct {
+	// Overwrite determines what happens when a destination file already exists.
+	Overwrite CopyOverwriteType
+	// Excludes is a list of glob patterns. Files and directories whose names match one of the patterns are not copied.
+	// Patterns are compared with paths relative to each source as described in Matcher.
+	Excludes []string
+	// Includes is a list of glob patterns. If not empty, only files whose names match one of the patterns are copied.
+	// Directories are not compared against Includes.
+	Includes []string
+	// Symlinks determines how symbolic links in the source are treated.
+	Symlinks SymlinkPolicy
+	// PreserveMetadata copies the permissions and modification times of directories, and the modification
+	// times of files. File permissions are always copied.
+	PreserveMetadata bool
+	// DryRun will report each file that would be copied and each directory that would be created,
+	// without changing anything on disk.
+	DryRun bool
+	// Emitter receives an Event for each file copied or skipped and each directory created.
+	Emitter Emitter
+	// Logger receives a message for each file copied or skipped and each directory created.
+	// If nil, nothing is logged.
+	Logger Logger
+	// Progress is called as each file is copied, after each chunk of data is written and after the file is complete.
+	// When a file is complete, FilesDone includes it.
+	Progress func(p CopyProgress)
+	// IgnoreFiles lists the names of ignore files, like GitIgnore and GofileIgnore. The files and directories
+	// that the ignore files inside a source directory list are not copied, and neither are the ignore files, as
+	// described in Matcher. If empty, ignore files are not read.
+	IgnoreFiles []string
+	// Filter, if not nil, selects the files that are copied by their type, size and modification time, and limits
+	// how deep the source directories are copied. Directories are only compared against Filter.MaxDepth.
+	Filter *Filter
+	// Hidden determines whether hidden files and directories are copied, as described in HiddenPolicy.
+	// A hidden source is not copied either if the policy excludes hidden files.
+	Hidden HiddenPolicy
+	// Dest is the file system that is written to. Destination paths are interpreted by Dest.
+	// If nil, the operating system's file system is used.
+	Dest WriteFS
+}
+
+// emit sends the event to the Emitter and the Logger.
+func (opts CopyOptions) emit(e Event) {
+	e.DryRun = opts.DryRun
+	if opts.Emitter != nil {
+		opts.Emitter.Emit(e)
+	}
+	if opts.Logger != nil {
+		logEvent(opts.Logger, e)
+	}
+}
+
+// Copy copies the src files or directories to the destination using the given options.
+//
+// If there is more than one source, the destination must be a directory that exists. The items listed
+// will be copied inside the destination directory.
+//
+// If there is only one source, the destination must be:
+//   - A directory that exists, in which case the source will be placed in the destination directory
+//   - A file that exists, in which case the source will overwrite the destination. The source must also be a single file.
+//   - A file that does not exist, but whose parent directory does exist, in which case the file will be copied
+//     and renamed to the destination.
+//
+// opts.Overwrite determines what happens when a file already exists. If a directory is over-writing another
+// directory, this will determine what happens when file names are duplicates. Note that old files in a directory
+// will not be deleted when a directory overwrites another directory. If you want old files to be deleted,
+// empty the destination directory first.
+//
+// The sources themselves are compared against opts.Excludes as described in Matcher.ExcludedRoot, so a source
+// directory whose name matches an exclude pattern is not copied at all.
+//
+// If one of the patterns in opts.Includes or opts.Excludes is malformed, nothing is copied and a *PatternError
+// is returned. If opts.Filter is not valid, nothing is copied and its error is returned.
+//
+// If ctx is cancelled, the copy stops and the context's error is returned. A file that is partially
+// copied when the context is cancelled is removed.
+//
+// The result describes the files that were copied and skipped. If an error occurs, the result describes
+// what was done before the error.
+func Copy(ctx context.Context, dst string, src []string, opts CopyOptions) (CopyResult, error) {
+	c := copier{ctx: ctx, opts: opts}
+	if err := c.validate(); err != nil {
+		return c.result, err
+	}
+	err := c.copy(dst, src)
+	return c.result, err
+}
+
+// CopyDirectoryContext copies the src directory into the dst directory as described in CopyDirectory,
+// using the given options. It is the same as calling Copy with src as the only source.
+func CopyDirectoryContext(ctx context.Context, src, dst string, opts CopyOptions) (CopyResult, error) {
+	return Copy(ctx, dst, []string{src}, opts)
+}
+
+// copier holds the state of a single copy operation.
+type copier struct {
+	ctx    context.Context
+	opts   CopyOptions
+	result CopyResult
+	// root is the source currently being copied. The include and exclude patterns are matched against
+	// paths relative to it.
+	root string
+	// ig applies the ignore files found in root
+	ig *ignorer
+	// m matches the include and exclude options. It is set by validate.
+	m *Matcher
+	// filesFound and bytesFound are reported to Progress as CopyProgress.FilesFound and CopyProgress.BytesFound
+	filesFound int
+	bytesFound int64
+}
+
+// found adds a file of the given size to the files found.
+func (c *copier) found(size int64) {
+	c.filesFound++
+	c.bytesFound += size
+}
+
+// setRoot starts the copy of a new source.
+func (c *copier) setRoot(root string) {
+	c.root = root
+	c.ig = newDirIgnorer(root, c.opts.IgnoreFiles)
+}
+
+// emit records the event in the result, and then reports it to the Emitter and the Logger.
+func (c *copier) emit(e Event) {
+	switch {
+	case e.Err != nil:
+	case e.Skipped != "":
+		c.result.Skipped = append(c.result.Skipped, SkippedFile{Source: e.Source, Destination: e.Destination, Reason: e.Skipped})
+	case e.Op == OpMkdir:
+		c.result.DirsCreated = append(c.result.DirsCreated, e.Destination)
+	default:
+		c.result.Copied = append(c.result.Copied, CopiedFile{Source: e.Source, Destination: e.Destination, Bytes: e.Bytes})
+		c.result.BytesWritten += e.Bytes
+	}
+	c.opts.emit(e)
+}
+
+// copy implements Copy.
+func (c *copier) copy(dst string, src []string) (err error) {
+	// Sanity checks
+	if dst == "" {
+		return ErrNoDestination
+	}
+
+	if len(src) == 0 || src[0] == "" {
+		return ErrNoSource
+	}
+
+	dstInfo, destErr := c.dest().Stat(dst)
+	srcInfo, srcErr := os.Stat(src[0])
+
+	if srcErr != nil {
+		return &CopyError{Source: src[0], Err: srcErr}
+	}
+
+	c.setRoot(src[0])
+	if len(src) > 1 || srcInfo.IsDir() {
+		if destErr != nil {
+			return &CopyError{Destination: dst, Err: destErr} // path doesn't exist?
+		}
+		if !dstInfo.IsDir() {
+			return &CopyError{Destination: dst, Err: ErrNotDirectory}
+		}
+
+		for _, f := range src {
+			c.setRoot(f)
+			err = c.copyTo(f, dst, "")
+			if err != nil {
+				return
+			}
+		}
+	} else {
+		if os.IsPathSeparator(dst[len(dst)-1]) {
+			// Definitely trying to point to a directory
+			if os.IsNotExist(destErr) {
+				return &CopyError{Destination: dst, Err: ErrDestinationNotFound}
+			}
+			err = c.copyTo(src[0], dst, "")
+			if err != nil {
+				return
+			}
+		} else {
+			// might be a destination directory, or a file
+			if os.IsNotExist(destErr) {
+				// Since it doesn't exist, we are going to assume we are trying to specify a file, since
+				// we have already checked above to see if we are trying to specify a directory with a slash at end.
+
+				// Check on parent directory
+				parentDir, fileName := filepath.Split(dst)
+				_, parentErr := c.dest().Stat(parentDir)
+				if parentErr != nil {
+					return &CopyError{Destination: dst, Err: ErrMissingParent}
+				}
+				// We are writing to a new file
+				err = c.copyTo(src[0], parentDir, fileName)
+				if err != nil {
+					return
+				}
+			} else {
+				// destination is a file or a directory that already exists
+				if dstInfo.IsDir() {
+					err = c.copyTo(src[0], dst, "")
+					if err != nil {
+						return
+					}
+				} else {
+					parentDir, fileName := filepath.Split(dst)
+					err = c.copyTo(src[0], parentDir, fileName)
+					if err != nil {
+						return
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// copyTo copies the src to the destination directory. The source can be a file or directory.
+// if a name is specified, src must be a file. The name will be the name of the file in the new directory.
+func (c *copier) copyTo(src string, destDir string, name string) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+
+	rel, isRoot := c.relPath(src)
+	isDir := IsDir(src)
+	if c.excluded(rel, isRoot, isDir) ||
+		!isRoot && (isDir && !c.m.couldInclude(rel) || c.ig.ignored(rel, isDir)) {
+		c.emit(Event{Op: OpCopy, Source: src, Skipped: SkipExcluded})
+		return nil
+	}
+
+	linkInfo, err := os.Lstat(src)
+	if err != nil {
+		return &CopyError{Source: src, Err: err}
+	}
+	if c.opts.Hidden.excludes() && (isDotName(filepath.Base(src)) || hiddenAttrs && hasHiddenAttr(src, linkInfo)) {
+		c.emit(Event{Op: OpCopy, Source: src, Skipped: SkipHidden})
+		return nil
+	}
+	if !c.filtered(rel, isRoot, linkInfo) {
+		c.emit(Event{Op: OpCopy, Source: src, Skipped: SkipFiltered})
+		return nil
+	}
+	if linkInfo.Mode()&os.ModeSymlink != 0 {
+		switch c.opts.Symlinks {
+		case SymlinkSkip:
+			c.emit(Event{Op: OpCopy, Source: src, Skipped: SkipSymlink})
+			return nil
+		case SymlinkCopy:
+			return c.copySymlink(src, destDir, name)
+		}
+	}
+
+	srcInfo, srcErr := os.Stat(src)
+
+	if srcErr != nil {
+		return &CopyError{Source: src, Err: srcErr}
+	}
+
+	if srcInfo.IsDir() && name != "" {
+		if name != filepath.Base(src) {
+			return &CopyError{Source: src, Destination: filepath.Join(destDir, name), Err: ErrDirectoryOntoFile}
+		}
+		p := filepath.Join(destDir, name)
+		dstInfo, dstErr := c.dest().Stat(p)
+		if (dstErr == nil || !os.IsNotExist(dstErr)) &&
+			!dstInfo.IsDir() {
+			return &CopyError{Source: src, Destination: p, Err: ErrDirectoryOntoFile}
+		}
+	}
+
+	if !srcInfo.IsDir() {
+		if !c.included(rel, isRoot) {
+			c.emit(Event{Op: OpCopy, Source: src, Skipped: SkipExcluded})
+			return nil
+		}
+		if isRoot {
+			// the files inside a directory are found when it is read
+			c.found(srcInfo.Size())
+		}
+		return c.copyFile(src, destDir, name)
+	}
+
+	// src is a directory, and destination is a directory
+	return c.copyDirectory(src, destDir)
+}
+
+// copyDirectory copies the src directory to the destination directory. The destination directory will be the
+// parent of the resulting directory.
+func (c *copier) copyDirectory(src, dst string) (err error) {
+	dstInfo, dstErr := c.dest().Stat(dst)
+	srcInfo, srcErr := os.Stat(src)
+
+	if srcErr != nil {
+		return &CopyError{Source: src, Err: srcErr}
+	}
+
+	if dstErr != nil {
+		if !(c.opts.DryRun && os.IsNotExist(dstErr)) {
+			return &CopyError{Destination: dst, Err: dstErr}
+		}
+		// In a dry run, the destination may be a directory that we would have created.
+	} else if !dstInfo.Mode().IsDir() {
+		return &CopyError{Destination: dst, Err: ErrNotDirectory}
+	}
+
+	if isOSFS(c.dest()) && isSubPath(src, dst) {
+		return &CopyError{Source: src, Destination: dst, Err: ErrCopyIntoSelf}
+	}
+
+	// create destination if needed
+	newPath := filepath.Join(dst, filepath.Base(src))
+
+	if err = c.makeDir(src, newPath); err != nil {
+		return
+	}
+
+	if rel, isRoot := c.relPath(src); !isRoot && !c.opts.Filter.descend(depth(rel)) {
+		// the directory is as deep as the filter allows, so it is copied empty
+		return c.finishDir(newPath, srcInfo)
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	list, err := f.Readdir(-1)
+	_ = f.Close()
+
+	for _, item := range list {
+		if c.willCopy(filepath.Join(src, item.Name()), item) {
+			c.found(item.Size())
+		}
+	}
+
+	for _, item := range list {
+		itemName := item.Name()
+		itemPath := filepath.Join(src, itemName)
+		err = c.copyTo(itemPath, newPath, itemName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.finishDir(newPath, srcInfo)
+}
+
+// makeDir creates the newPath directory if it does not exist. src is the directory being copied.
+func (c *copier) makeDir(src, newPath string) error {
+	newInfo, err := c.dest().Stat(newPath)
+	if err == nil || !os.IsNotExist(err) {
+		// path exists
+		if !newInfo.IsDir() {
+			return &CopyError{Source: src, Destination: newPath, Err: ErrDirectoryOntoFile}
+		}
+	} else if c.opts.DryRun {
+		c.emit(Event{Op: OpMkdir, Destination: newPath})
+	} else {
+		err = c.dest().Mkdir(newPath, 0755)
+		if err != nil {
+			return &CopyError{Destination: newPath, Err: err}
+		}
+		c.emit(Event{Op: OpMkdir, Destination: newPath})
+	}
+	return nil
+}
+
+// finishDir sets the permissions and modification time of a copied directory if metadata is being preserved.
+// This is done after the contents are copied, so that the permissions do not prevent the copy, and the copy does
+// not change the modification time.
+func (c *copier) finishDir(newPath string, srcInfo fs.FileInfo) error {
+	if !c.opts.PreserveMetadata || c.opts.DryRun {
+		return nil
+	}
+	if err := c.dest().Chmod(newPath, srcInfo.Mode().Perm()); err != nil {
+		return err
+	}
+	return c.dest().Chtimes(newPath, srcInfo.ModTime(), srcInfo.ModTime())
+}
+
+// copyFile copies the given file to the destination directory.
+//
+// If a name is given, it will rename the file.
+// It does no checks to see if the destination directory exists.
+// If the overwrite policy would prevent the file from being copied, then the copy does not happen and
+// error is nil.
+func (c *copier) copyFile(src string, destDir string, name string) error {
+	srcInfo, srcErr := os.Stat(src)
+	if srcErr != nil {
+		return srcErr
+	}
+	if srcInfo.IsDir() {
+		return &CopyError{Source: src, Err: ErrNotFile}
+	}
+	if name == "" {
+		name = filepath.Base(src)
+	}
+	destName := filepath.Join(destDir, name)
+	return c.writeFile(src, srcInfo, srcInfo.Mode().Perm(), func() (io.ReadCloser, error) { return os.Open(src) }, destName)
+}
+
+// writeFile writes the src file to destName with the given permissions. open opens the source for reading, and
+// srcInfo describes it. If the overwrite policy would prevent the file from being copied, then the copy does not
+// happen and error is nil.
+func (c *copier) writeFile(src string, srcInfo fs.FileInfo, perm fs.FileMode, open func() (io.ReadCloser, error), destName string) error {
+	destInfo, destErr := c.dest().Stat(destName)
+	if destErr == nil {
+		// destination exists
+		if skip := c.skipExisting(srcInfo, destInfo); skip != "" {
+			c.emit(Event{Op: OpCopy, Source: src, Destination: destName, Skipped: skip})
+			// it was found, but it will not be written
+			c.filesFound--
+			c.bytesFound -= srcInfo.Size()
+			return nil
+		}
+		if c.opts.DryRun {
+			c.emit(Event{Op: OpCopy, Source: src, Destination: destName, Bytes: srcInfo.Size()})
+			return nil
+		}
+		// prepare for copy by deleting in case permissions are different
+		if err := c.dest().Remove(destName); err != nil {
+			return err
+		}
+	} else if c.opts.DryRun {
+		c.emit(Event{Op: OpCopy, Source: src, Destination: destName, Bytes: srcInfo.Size()})
+		return nil
+	}
+
+	start := time.Now()
+	from, err := open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = from.Close()
+	}()
+
+	to, err := c.dest().Create(destName, perm)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = to.Close()
+	}()
+
+	p := CopyProgress{
+		Source:      src,
+		Destination: destName,
+		FileSize:    srcInfo.Size(),
+		FilesDone:   len(c.result.Copied),
+		FilesFound:  c.filesFound,
+		BytesFound:  c.bytesFound,
+	}
+	n, err := c.copyData(to, from, p)
+	if err != nil {
+		// do not leave a partial file behind
+		_ = to.Close()
+		_ = c.dest().Remove(destName)
+		return err
+	}
+
+	err = to.Close()
+	if err != nil {
+		return err
+	}
+
+	if c.opts.PreserveMetadata {
+		if err = c.dest().Chtimes(destName, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	c.emit(Event{Op: OpCopy, Source: src, Destination: destName, Bytes: n, Duration: time.Since(start)})
+	if c.opts.Progress != nil {
+		p.FileBytes = n
+		p.FilesDone = len(c.result.Copied)
+		p.TotalBytes = c.result.BytesWritten
+		c.opts.Progress(p)
+	}
+
+	return nil
+}
+
+// copyBufferSize is the amount of data copied between checks for cancellation and reports of progress.
+const copyBufferSize = 256 * 1024
+
+// copyData copies r to w. It stops if the context is cancelled, and reports progress after each
+// chunk of data is written. p describes the file being copied.
+func (c *copier) copyData(w io.Writer, r io.Reader, p CopyProgress) (n int64, err error) {
+	buf := make([]byte, copyBufferSize)
+	for {
+		if err = c.ctx.Err(); err != nil {
+			return
+		}
+		nr, readErr := r.Read(buf)
+		if nr > 0 {
+			nw, writeErr := w.Write(buf[:nr])
+			n += int64(nw)
+			if writeErr != nil {
+				return n, writeErr
+			}
+			if nw != nr {
+				return n, io.ErrShortWrite
+			}
+			if c.opts.Progress != nil {
+				p.FileBytes = n
+				p.TotalBytes = c.result.BytesWritten + n
+				c.opts.Progress(p)
+			}
+		}
+		if readErr == io.EOF {
+			return n, nil
+		}
+		if readErr != nil {
+			return n, readErr
+		}
+	}
+}
+
+// copySymlink creates a link in destDir that has the same target as the src link.
+func (c *copier) copySymlink(src string, destDir string, name string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = filepath.Base(src)
+	}
+	destName := filepath.Join(destDir, name)
+	dest, ok := c.dest().(SymlinkFS)
+	if !ok {
+		c.emit(Event{Op: OpSymlink, Source: src, Destination: destName, Skipped: SkipSymlink})
+		return nil
+	}
+	if destInfo, destErr := dest.Lstat(destName); destErr == nil {
+		srcInfo, _ := os.Lstat(src)
+		if skip := c.skipExisting(srcInfo, destInfo); skip != "" {
+			c.emit(Event{Op: OpSymlink, Source: src, Destination: destName, Skipped: skip})
+			return nil
+		}
+		if !c.opts.DryRun {
+			if err = dest.Remove(destName); err != nil {
+				return err
+			}
+		}
+	}
+	if !c.opts.DryRun {
+		if err = dest.Symlink(target, destName); err != nil {
+			return err
+		}
+	}
+	c.emit(Event{Op: OpSymlink, Source: src, Destination: destName, Detail: target})
+	return nil
+}
+
+// willCopy returns true if the item found in a source directory at p, which info describes without following
+// symbolic links, is a file that will be copied unless it already exists. It is used to count the files found.
+func (c *copier) willCopy(p string, info fs.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	rel, _ := c.relPath(p)
+	if c.m.Excluded(rel, false) || c.ig.ignored(rel, false) || !c.m.Included(rel) {
+		return false
+	}
+	if c.opts.Hidden.excludes() && (isDotName(info.Name()) || hiddenAttrs && hasHiddenAttr(p, info)) {
+		return false
+	}
+	return c.filtered(rel, false, info)
+}
+
+// validate builds the Matcher for the include and exclude options, and checks the patterns and the filter in
+// the options.
+func (c *copier) validate() error {
+	c.m = NewMatcher(c.opts.Includes, c.opts.Excludes)
+	if err := c.m.Validate(); err != nil {
+		return err
+	}
+	return c.opts.Filter.Validate()
+}
+
+// filtered returns true if the source described by info, whose path is rel relative to the root, is selected by
+// the Filter. Directories are only compared against Filter.MaxDepth, and a root directory is always selected.
+func (c *copier) filtered(rel string, isRoot bool, info fs.FileInfo) bool {
+	n := 0
+	if !isRoot {
+		n = depth(rel)
+	}
+	if info.IsDir() {
+		return c.opts.Filter.inDepth(n)
+	}
+	return c.opts.Filter.Match(info, n)
+}
+
+// relPath returns the path of src relative to the root of the copy, or src and true if it is the root.
+func (c *copier) relPath(src string) (string, bool) {
+	if src == c.root {
+		return src, true
+	}
+	rel, err := filepath.Rel(c.root, src)
+	if err != nil {
+		return src, true
+	}
+	return rel, false
+}
+
+// excluded returns true if the path matches one of the exclude patterns. If isRoot is true, the path
+// is the root of the copy, and is matched as described in Matcher.ExcludedRoot.
+func (c *copier) excluded(p string, isRoot bool, isDir bool) bool {
+	if isRoot {
+		return c.m.ExcludedRoot(p, isDir)
+	}
+	return c.m.Excluded(p, isDir)
+}
+
+// included returns true if the file matches the include patterns.
+func (c *copier) included(p string, isRoot bool) bool {
+	if isRoot {
+		return c.m.included(p, true)
+	}
+	return c.m.Included(p)
+}
+
+// dest returns the file system being written to.
+func (c *copier) dest() WriteFS {
+	if c.opts.Dest == nil {
+		return OSFS{}
+	}
+	return c.opts.Dest
+}
+
+// skipExisting returns the reason an existing destination should not be overwritten by the source,
+// or an empty string if it should be overwritten.
+func (c *copier) skipExisting(srcInfo, destInfo fs.FileInfo) string {
+	if c.opts.Overwrite == CopyDoNotOverwrite {
+		return SkipExists
+	} else if c.opts.Overwrite == CopyOverwriteOnlyIfNewer {
+		modSrc := srcInfo.ModTime()
+		modDest := destInfo.ModTime()
+
+		if modSrc.Before(modDest) || modSrc.Equal(modDest) {
+			return SkipNotNewer
+		}
+	}
+	return ""
+}
+
+// isSubPath returns true if p is the same as dir, or is inside of dir.
+func isSubPath(dir, p string) bool {
+	dir, err1 := filepath.Abs(dir)
+	p, err2 := filepath.Abs(p)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return p == dir || strings.HasPrefix(p, dir+string(filepath.Separator))
+}
@@ -0,0 +1,306 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyIncludes(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	opts := CopyOptions{Includes: []string{"*.abc"}}
+	if _, err = Copy(context.Background(), tempDir, []string{testDataDir1()}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(tempDir, "dir1", "a", "t3.abc")); err != nil {
+		t.Error(err)
+	}
+	if _, err = os.Stat(filepath.Join(tempDir, "dir1", "a", "t1.txt")); err == nil {
+		t.Error("File t1.txt should not have been copied")
+	}
+}
+
+func TestCopySymlinks(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	src := filepath.Join(tempDir, "src")
+	_ = os.Mkdir(src, 0777)
+	_ = os.WriteFile(filepath.Join(src, "file.txt"), []byte("test"), 0666)
+	if err = os.Symlink("file.txt", filepath.Join(src, "link.txt")); err != nil {
+		t.Skip("symbolic links are not supported: " + err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		policy   SymlinkPolicy
+		wantMode os.FileMode
+		wantErr  bool
+	}{
+		{"follow", SymlinkFollow, 0, false},
+		{"copy", SymlinkCopy, os.ModeSymlink, false},
+		{"skip", SymlinkSkip, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(tempDir, tt.name)
+			_ = os.Mkdir(dst, 0777)
+			if _, err := Copy(context.Background(), dst, []string{src}, CopyOptions{Symlinks: tt.policy}); err != nil {
+				t.Fatal(err)
+			}
+			info, err := os.Lstat(filepath.Join(dst, "src", "link.txt"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lstat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && info.Mode()&os.ModeSymlink != tt.wantMode {
+				t.Errorf("Wrong link mode: %v", info.Mode())
+			}
+		})
+	}
+}
+
+func TestCopyPreserveMetadata(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	src := filepath.Join(tempDir, "src.txt")
+	_ = os.WriteFile(src, []byte("test"), 0666)
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	_ = os.Chtimes(src, modTime, modTime)
+
+	dst := filepath.Join(tempDir, "dst.txt")
+	if _, err = Copy(context.Background(), dst, []string{src}, CopyOptions{PreserveMetadata: true}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("Modification time not preserved: %v", info.ModTime())
+	}
+}
+
+func TestCopyNoPermissions(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src.txt")
+	if err := os.WriteFile(src, []byte("test"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := os.Open(src); err != nil {
+		t.Skip("cannot read a file without permissions")
+	} else {
+		_ = f.Close()
+	}
+	dest := NewMemFS()
+	if _, err := Copy(context.Background(), "dst.txt", []string{src}, CopyOptions{Dest: dest}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := dest.Stat("dst.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0 {
+		t.Errorf("Permissions changed to %v", info.Mode().Perm())
+	}
+}
+
+func TestCopyProgressAndCancel(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	var last CopyProgress
+	opts := CopyOptions{Progress: func(p CopyProgress) { last = p }}
+	if _, err = Copy(context.Background(), tempDir, []string{filepath.Join(testDataDir1(), "a")}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if last.FilesDone != 3 || last.TotalBytes == 0 || last.FilesFound != 3 || last.BytesFound != last.TotalBytes {
+		t.Errorf("Wrong progress: %v", last)
+	}
+
+	// the files that are excluded or already exist are not counted as found
+	opts.Excludes = []string{"*.abc"}
+	if _, err = Copy(context.Background(), tempDir, []string{filepath.Join(testDataDir1(), "a"), testDataDir1()}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if last.FilesDone != 5 || last.FilesFound != 5 || last.BytesFound != last.TotalBytes {
+		t.Errorf("Wrong progress: %v", last)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Copy(ctx, tempDir, []string{testDataDir2()}, CopyOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled error, got %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(tempDir, "dir2")); err == nil {
+		t.Error("Cancelled copy created a directory")
+	}
+}
+
+func TestCopyResult(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	opts := CopyOptions{Excludes: []string{"*.abc"}}
+	src := []string{filepath.Join(testDataDir1(), "a")}
+	result, err := Copy(context.Background(), tempDir, src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Changed() ||
+		len(result.Copied) != 2 ||
+		len(result.Skipped) != 1 ||
+		result.Skipped[0].Reason != SkipExcluded ||
+		len(result.DirsCreated) != 1 ||
+		result.BytesWritten == 0 {
+		t.Errorf("Wrong result: %v", result)
+	}
+
+	result, err = Copy(context.Background(), tempDir, src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed() || len(result.Skipped) != 3 || result.Skipped[0].Reason == "" {
+		t.Errorf("Wrong result: %v", result)
+	}
+}
+
+func TestCopyErrors(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	t1 := filepath.Join("testdata", "t1.txt")
+	t2 := filepath.Join("testdata", "t2.txt")
+	_ = os.WriteFile(filepath.Join(tempDir, "dir1"), []byte("file"), 0666)
+	_ = os.Mkdir(filepath.Join(tempDir, "sub"), 0777)
+	tests := []struct {
+		name string
+		dst  string
+		src  []string
+		want error
+	}{
+		{"no source", tempDir, nil, ErrNoSource},
+		{"no dest", "", []string{t1}, ErrNoDestination},
+		{"bad src", tempDir, []string{"random"}, fs.ErrNotExist},
+		{"bad second src", tempDir, []string{t1, "random"}, fs.ErrNotExist},
+		{"bad dest", "random", []string{t1, t2}, fs.ErrNotExist},
+		{"file dest", t1, []string{t1, t2}, ErrNotDirectory},
+		{"bad dir dest", "random1" + string(filepath.Separator), []string{t1}, ErrDestinationNotFound},
+		{"bad parent dir", filepath.Join("random1", "bad2"), []string{t1}, ErrMissingParent},
+		{"dir onto file", tempDir, []string{testDataDir1()}, ErrDirectoryOntoFile},
+		{"into self", filepath.Join(tempDir, "sub"), []string{tempDir}, ErrCopyIntoSelf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Copy(context.Background(), tt.dst, tt.src, CopyOptions{})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Copy() error = %v, want %v", err, tt.want)
+			}
+			var copyErr *CopyError
+			if tt.want != ErrNoSource && tt.want != ErrNoDestination && !errors.As(err, &copyErr) {
+				t.Errorf("Copy() error %v is not a *CopyError", err)
+			}
+		})
+	}
+
+	_, err = Copy(context.Background(), filepath.Join(tempDir, "sub"), []string{tempDir}, CopyOptions{Dest: &OSFS{}})
+	if !errors.Is(err, ErrCopyIntoSelf) {
+		t.Errorf("Copy() into self with a *OSFS error = %v", err)
+	}
+}
+
+func TestModuleError(t *testing.T) {
+	_, err := GetModulePath("example.com/mod/a", map[string]string{"example.com/mod": ""})
+	var modErr *ModuleError
+	if !errors.Is(err, ErrModuleNotDownloaded) || !errors.As(err, &modErr) || modErr.Module != "example.com/mod" {
+		t.Errorf("Wrong error: %v", err)
+	}
+}
+
+func TestCopyCancelWithinFile(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	src := filepath.Join(tempDir, "big.bin")
+	if err = os.WriteFile(src, make([]byte, copyBufferSize*3), 0666); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(tempDir, "big2.bin")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	opts := CopyOptions{Progress: func(p CopyProgress) {
+		calls++
+		if p.FileBytes != copyBufferSize || p.TotalBytes != copyBufferSize || p.FileSize != copyBufferSize*3 {
+			t.Errorf("Wrong progress: %v", p)
+		}
+		cancel()
+	}}
+	_, err = Copy(ctx, dst, []string{src}, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Progress called %d times", calls)
+	}
+	if _, err = os.Stat(dst); err == nil {
+		t.Error("Partial file was not removed")
+	}
+}
+
+func TestCopyDirectoryContext(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	var progress []CopyProgress
+	opts := CopyOptions{Progress: func(p CopyProgress) { progress = append(progress, p) }}
+	result, err := CopyDirectoryContext(context.Background(), testDataDir2(), tempDir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Copied) != 1 || len(progress) != 2 || progress[1].FilesDone != 1 {
+		t.Errorf("Wrong result: %v %v", result, progress)
+	}
+}
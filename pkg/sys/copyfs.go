@@ -0,0 +1,154 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// CopyFS copies the contents of the root directory in fsys, like an embed.FS, into the dst directory.
+// dst must exist. root uses forward slashes as described in io/fs, and "." or "" copies the whole file system.
+//
+// The options are applied as they are in Copy: existing files are treated according to opts.Overwrite,
+// and files and directories are skipped if their names match opts.Excludes. Files are written with the
+// permissions reported by fsys, or 0644 if fsys does not report permissions. Since an fs.FS cannot report the
+// target of a symbolic link, links are only copied if opts.Symlinks is SymlinkFollow and they point to a file.
+func CopyFS(fsys fs.FS, root string, dst string, opts CopyOptions) (CopyResult, error) {
+	return CopyFSContext(context.Background(), fsys, root, dst, opts)
+}
+
+// CopyFSContext is like CopyFS, but stops when ctx is cancelled, as described in Copy.
+func CopyFSContext(ctx context.Context, fsys fs.FS, root string, dst string, opts CopyOptions) (CopyResult, error) {
+	c := copier{ctx: ctx, opts: opts}
+	if err := c.validate(); err != nil {
+		return c.result, err
+	}
+	err := c.copyFS(fsys, root, dst)
+	return c.result, err
+}
+
+// copyFS implements CopyFSContext.
+func (c *copier) copyFS(fsys fs.FS, root string, dst string) error {
+	if root == "" {
+		root = "."
+	}
+	dstInfo, err := c.dest().Stat(dst)
+	if err != nil {
+		return &CopyError{Destination: dst, Err: err}
+	}
+	if !dstInfo.IsDir() {
+		return &CopyError{Destination: dst, Err: ErrNotDirectory}
+	}
+
+	type copiedDir struct {
+		path string
+		info fs.FileInfo
+	}
+	var dirs []copiedDir
+
+	if sub, err := fs.Sub(fsys, root); err == nil {
+		c.ig = newIgnorer(sub, c.opts.IgnoreFiles)
+	}
+
+	err = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = c.ctx.Err(); err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel := strings.TrimPrefix(p, root+"/")
+		if root == "." {
+			rel = p
+		}
+		destName := filepath.Join(dst, filepath.FromSlash(rel))
+
+		if c.m.Excluded(rel, d.IsDir()) || d.IsDir() && !c.m.couldInclude(rel) || c.ig.ignored(rel, d.IsDir()) {
+			c.emit(Event{Op: OpCopy, Source: p, Skipped: SkipExcluded})
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if c.opts.Hidden.excludes() && isHiddenEntry("", d) {
+			c.emit(Event{Op: OpCopy, Source: p, Skipped: SkipHidden})
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if c.opts.Filter != nil {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !c.filtered(rel, false, info) {
+				c.emit(Event{Op: OpCopy, Source: p, Skipped: SkipFiltered})
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 && c.opts.Symlinks != SymlinkFollow {
+			c.emit(Event{Op: OpCopy, Source: p, Skipped: SkipSymlink})
+			return nil
+		}
+
+		if d.IsDir() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			dirs = append(dirs, copiedDir{destName, info})
+			if err = c.makeDir(p, destName); err == nil && !c.opts.Filter.descend(depth(rel)) {
+				return fs.SkipDir
+			}
+			return err
+		}
+
+		// fs.Stat follows symbolic links
+		info, err := fs.Stat(fsys, p)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			c.emit(Event{Op: OpCopy, Source: p, Skipped: SkipSymlink})
+			return nil
+		}
+		if !c.m.Included(rel) {
+			c.emit(Event{Op: OpCopy, Source: p, Skipped: SkipExcluded})
+			return nil
+		}
+		c.found(info.Size())
+		perm := info.Mode().Perm()
+		if perm == 0 {
+			// the file system does not report permissions
+			perm = 0644
+		}
+		return c.writeFile(p, info, perm, func() (io.ReadCloser, error) { return fsys.Open(p) }, destName)
+	})
+	if err != nil {
+		return err
+	}
+
+	// set directory metadata from the bottom up, so that setting a parent is not undone by changes to a child
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err = c.finishDir(dirs[i].path, dirs[i].info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCopyFS(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	fsys := fstest.MapFS{
+		"skel/README.md":      {Data: []byte("readme"), Mode: 0640},
+		"skel/run.sh":         {Data: []byte("#!/bin/sh"), Mode: 0755},
+		"skel/web/index.html": {Data: []byte("<html>")},
+		"skel/web/index.tmp":  {Data: []byte("tmp")},
+		"other/file.txt":      {Data: []byte("other")},
+	}
+
+	opts := CopyOptions{Excludes: []string{"*.tmp"}}
+	result, err := CopyFS(fsys, "skel", tempDir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Copied) != 3 || len(result.DirsCreated) != 1 || len(result.Skipped) != 1 {
+		t.Errorf("Wrong result: %v", result)
+	}
+	if b, _ := os.ReadFile(filepath.Join(tempDir, "web", "index.html")); string(b) != "<html>" {
+		t.Error("File content does not match")
+	}
+	if _, err = os.Stat(filepath.Join(tempDir, "web", "index.tmp")); err == nil {
+		t.Error("Excluded file was copied")
+	}
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(filepath.Join(tempDir, "run.sh"))
+		if info.Mode().Perm() != 0755 {
+			t.Errorf("Mode not kept: %v", info.Mode())
+		}
+		info, _ = os.Stat(filepath.Join(tempDir, "web", "index.html"))
+		if info.Mode().Perm() != 0644 {
+			t.Errorf("Default mode not used: %v", info.Mode())
+		}
+	}
+
+	// copying again should not overwrite
+	_ = os.WriteFile(filepath.Join(tempDir, "README.md"), []byte("changed"), 0666)
+	result, err = CopyFS(fsys, "skel", tempDir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Copied) != 0 || len(result.Skipped) != 4 {
+		t.Errorf("Wrong result: %v", result)
+	}
+	if b, _ := os.ReadFile(filepath.Join(tempDir, "README.md")); string(b) != "changed" {
+		t.Error("File was overwritten")
+	}
+
+	opts.Overwrite = CopyOverwrite
+	if _, err = CopyFS(fsys, ".", tempDir, opts); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(tempDir, "skel", "README.md")); string(b) != "readme" {
+		t.Error("Whole file system was not copied")
+	}
+
+	if _, err = CopyFS(fsys, "skel", filepath.Join(tempDir, "random"), opts); err == nil {
+		t.Error("Error expected")
+	}
+}
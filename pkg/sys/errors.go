@@ -0,0 +1,104 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the functions in this package. Use errors.Is to test for them, since they are usually
+// wrapped in a CopyError or ModuleError that records the paths involved.
+var (
+	// ErrNoDestination is returned when a copy is given an empty destination.
+	ErrNoDestination = errors.New("no destination specified")
+	// ErrNoSource is returned when a copy is given no source files.
+	ErrNoSource = errors.New("no source files specified")
+	// ErrNotDirectory is returned when a destination must be a directory, but is a file.
+	ErrNotDirectory = errors.New("the destination must be a directory")
+	// ErrDestinationNotFound is returned when a destination that is specified as a directory does not exist.
+	ErrDestinationNotFound = errors.New("the destination directory does not exist")
+	// ErrMissingParent is returned when copying to a new file whose parent directory does not exist.
+	ErrMissingParent = errors.New("the parent directory of a new file must exist")
+	// ErrNotFile is returned when a source must be a file, but is a directory.
+	ErrNotFile = errors.New("the source is not a file")
+	// ErrDirectoryOntoFile is returned when copying a directory to a destination that is a file.
+	ErrDirectoryOntoFile = errors.New("cannot copy a directory onto a file")
+	// ErrCopyIntoSelf is returned when the destination of a directory copy is inside the source directory.
+	ErrCopyIntoSelf = errors.New("the destination directory is not allowed to be in the source directory")
+	// ErrModuleNotDownloaded is returned by GetModulePath when a module is listed, but its source is not
+	// in the module cache.
+	ErrModuleNotDownloaded = errors.New("the module is in the cache, but is not installed")
+	// ErrUnterminatedQuote is returned by ExecuteShellCommand when a command has an unterminated quote.
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+	// ErrNoMatch is returned when a glob pattern does not match any files, and that is not allowed.
+	ErrNoMatch = errors.New("no files match the pattern")
+	// ErrNotSupported is returned by a WriteFS that cannot perform an operation, like changing a file
+	// that has already been written to an archive.
+	ErrNotSupported = errors.New("operation not supported")
+)
+
+// CopyError records a failed copy operation along with the paths involved. Err is one of the
+// errors above, or an error from the os package.
+type CopyError struct {
+	Source      string
+	Destination string
+	Err         error
+}
+
+// Error returns the error message.
+func (e *CopyError) Error() string {
+	switch {
+	case e.Source != "" && e.Destination != "":
+		return fmt.Sprintf("%s: copying %s to %s", e.Err.Error(), e.Source, e.Destination)
+	case e.Destination != "":
+		return fmt.Sprintf("%s: %s", e.Err.Error(), e.Destination)
+	case e.Source != "":
+		return fmt.Sprintf("%s: %s", e.Err.Error(), e.Source)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the cause of the error.
+func (e *CopyError) Unwrap() error {
+	return e.Err
+}
+
+// PatternError records a problem with a glob pattern. Err is filepath.ErrBadPattern if the pattern is malformed,
+// or ErrNoMatch if the pattern matched nothing.
+type PatternError struct {
+	Pattern string
+	Err     error
+}
+
+// Error returns the error message.
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err.Error(), e.Pattern)
+}
+
+// Unwrap returns the cause of the error.
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
+
+// ModuleError records a problem with a module.
+type ModuleError struct {
+	Module string
+	Err    error
+}
+
+// Error returns the error message.
+func (e *ModuleError) Error() string {
+	if errors.Is(e.Err, ErrModuleNotDownloaded) {
+		return fmt.Sprintf("module %s is in the cache, but is not installed. Possibly you only installed its application? "+
+			"Install the module again using go get -u %[1]s", e.Module)
+	}
+	return fmt.Sprintf("module %s: %s", e.Module, e.Err.Error())
+}
+
+// Unwrap returns the cause of the error.
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
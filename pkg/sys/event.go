@@ -0,0 +1,96 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"fmt"
+	"time"
+)
+
+// Operations reported in an Event by the functions in this package.
+const (
+	OpCopy    = "copy"
+	OpMkdir   = "mkdir"
+	OpSymlink = "symlink"
+)
+
+// Reasons reported in Event.Skipped when a file is not copied.
+const (
+	// SkipExists indicates the destination exists and the overwrite policy prevents replacing it.
+	SkipExists = "exists"
+	// SkipNotNewer indicates the destination exists and is at least as new as the source.
+	SkipNotNewer = "not newer"
+	// SkipExcluded indicates the source matched an exclude pattern, or did not match an include pattern.
+	SkipExcluded = "excluded"
+	// SkipSymlink indicates the source is a symbolic link and links are being skipped.
+	SkipSymlink = "symlink"
+	// SkipFiltered indicates the source was not selected by the Filter in the options.
+	SkipFiltered = "filtered"
+	// SkipHidden indicates the source is hidden, and hidden files are not being copied.
+	SkipHidden = "hidden"
+)
+
+// Event describes a single file system action that was performed, would have been performed in a dry run,
+// or was skipped.
+type Event struct {
+	// Op is the operation, like OpCopy.
+	Op string
+	// Source is the file the operation read from, if any.
+	Source string
+	// Destination is the file or directory the operation wrote to, if any.
+	Destination string
+	// Bytes is the number of bytes written.
+	Bytes int64
+	// Skipped is the reason the operation was not performed, or empty if it was performed.
+	Skipped string
+	// Duration is how long the operation took.
+	Duration time.Duration
+	// Err is the error the operation produced, if any.
+	Err error
+	// DryRun is true if the operation was only reported and not performed.
+	DryRun bool
+	// Detail is additional information, like the output of a command.
+	Detail string
+}
+
+// String returns a human-readable description of the event.
+func (e Event) String() string {
+	if e.Err != nil {
+		if e.Source == "" {
+			return fmt.Sprintf("Error during %s of %s: %s", e.Op, e.Destination, e.Err.Error())
+		}
+		return fmt.Sprintf("Error during %s of %s: %s", e.Op, e.Source, e.Err.Error())
+	}
+	if e.Skipped != "" {
+		return fmt.Sprintf("Skipped %s: %s", e.Source, e.Skipped)
+	}
+	switch e.Op {
+	case OpCopy:
+		if e.DryRun {
+			return fmt.Sprintf("Would copy %s to %s", e.Source, e.Destination)
+		}
+		return fmt.Sprintf("Copied %s to %s", e.Source, e.Destination)
+	case OpSymlink:
+		if e.DryRun {
+			return fmt.Sprintf("Would link %s to %s", e.Destination, e.Detail)
+		}
+		return fmt.Sprintf("Linked %s to %s", e.Destination, e.Detail)
+	case OpMkdir:
+		if e.DryRun {
+			return fmt.Sprintf("Would create directory %s", e.Destination)
+		}
+		return fmt.Sprintf("Created directory %s", e.Destination)
+	}
+	s := e.Op + " " + e.Source
+	if e.Destination != "" {
+		s += " " + e.Destination
+	}
+	return s
+}
+
+// Emitter receives the events generated while processing files.
+type Emitter interface {
+	Emit(e Event)
+}
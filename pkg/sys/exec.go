@@ -6,7 +6,9 @@ package sys
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -24,13 +26,19 @@ import (
 // To include a quote character, use the other kind of quote. For example, to include a single quote, surround with double quotes.
 // This does not support recursive quotes. For that, you just will need to revert to the exec.Command function.
 func ExecuteShellCommand(command string) (result []byte, err error) {
+	return ExecuteShellCommandContext(context.Background(), command)
+}
+
+// ExecuteShellCommandContext is like ExecuteShellCommand, but the command is killed if ctx is cancelled
+// before the command completes.
+func ExecuteShellCommandContext(ctx context.Context, command string) (result []byte, err error) {
 	var parts []string
 	parts, err = splitCommandParts(command)
 	if len(parts) == 0 || err != nil {
 		return
 	}
 
-	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
 
 	result, err = cmd.Output()
 	return
@@ -51,7 +59,7 @@ func splitCommandParts(command string) (parts []string, err error) {
 			i2 := strings.Index(cur[i+1:], lookFor)
 			if i2 == -1 {
 				// An error, an unterminated quote
-				err = fmt.Errorf("unterminated quote at: %s", cur[i+1:])
+				err = fmt.Errorf("%w at: %s", ErrUnterminatedQuote, cur[i+1:])
 				return
 			}
 			var parts2 []string
@@ -99,7 +107,7 @@ func ModulePaths() (ret map[string]string, err error) {
 					if err2 == io.EOF {
 						return
 					}
-					return nil, fmt.Errorf("error unpacking json from go list command.\n%s\n%s", string(outText), err.Error())
+					return nil, fmt.Errorf("error unpacking json from go list command.\n%s\n%w", string(outText), err2)
 				}
 				ret[v.Path] = v.Dir
 			}
@@ -107,8 +115,11 @@ func ModulePaths() (ret map[string]string, err error) {
 		return
 	} else {
 		// unpack standard error
-		stdErr := string(err.(*exec.ExitError).Stderr)
-		return nil, fmt.Errorf("error getting module list %s", stdErr)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("error getting module list %s: %w", string(exitErr.Stderr), err)
+		}
+		return nil, fmt.Errorf("error getting module list: %w", err)
 	}
 }
 
@@ -118,13 +129,14 @@ func ModulePaths() (ret map[string]string, err error) {
 // path uses the native path separator.
 //
 // If the path is not a module path, the path will be returned unchanged.
+// If the module is listed but its source has not been downloaded, a *ModuleError wrapping
+// ErrModuleNotDownloaded is returned.
 func GetModulePath(path string, modules map[string]string) (newPath string, err error) {
 	newPath = path
 	for modPath, dir := range modules {
 		if len(modPath) <= len(path) && path[:len(modPath)] == modPath { // if the path starts with a module path, replace it with the actual directory
 			if dir == "" {
-				err = fmt.Errorf("module %s is in the cache, but is not installed. Possibly you only installed its application? "+
-					"Install the module again using go get -u %[1]s", modPath)
+				err = &ModuleError{Module: modPath, Err: ErrModuleNotDownloaded}
 			}
 			path = filepath.Join(dir, path[len(modPath):])
 			newPath = filepath.FromSlash(path)
@@ -5,6 +5,7 @@
 package sys
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"testing"
@@ -126,3 +127,11 @@ func TestImportPath(t *testing.T) {
 	}
 
 }
+
+func TestExecuteShellCommandContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ExecuteShellCommandContext(ctx, "go version"); err == nil {
+		t.Error("error expected")
+	}
+}
@@ -5,9 +5,8 @@
 package sys
 
 import (
-	"fmt"
+	"context"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -29,11 +28,58 @@ func SplitList(s string) (list []string) {
 	return
 }
 
+// SplitPatterns splits a list of include or exclude patterns the way SplitList does, except that a regular expression
+// pattern, one that starts with re: or !re:, continues to the next semicolon, so that it can contain colons.
+func SplitPatterns(s string) (list []string) {
+	for _, part := range strings.Split(s, ";") {
+		for part != "" {
+			if strings.HasPrefix(part, regexPrefix) || strings.HasPrefix(part, "!"+regexPrefix) {
+				list = append(list, part)
+				break
+			}
+			item, rest, _ := strings.Cut(part, ":")
+			if item != "" {
+				list = append(list, item)
+			}
+			part = rest
+		}
+	}
+	return
+}
+
+// ReadFileList reads a list of files from r, with one file on each line. Empty lines are skipped, and
+// a carriage return at the end of a line is removed. If nul is true, the files are separated by NUL characters instead,
+// as written by git ls-files -z or find -print0, which allows file names that contain newlines.
+//
+// The items are the names of files, not patterns. Do not pass them to ModuleExpandFileList, since environment
+// variables, braces and glob characters are valid in file names. Use GetModulePath to replace a module path at the
+// start of an item.
+func ReadFileList(r io.Reader, nul bool) (list []string, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	sep := "\n"
+	if nul {
+		sep = "\x00"
+	}
+	for _, item := range strings.Split(string(data), sep) {
+		if !nul {
+			item = strings.TrimSuffix(item, "\r")
+		}
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
 // ModuleExpandFileList will do the following given a list of arguments that represent command line arguments
 // that would be a list of
 // files, directories, or glob patterns:
 //
 //	replace any environment variables with their values
+//	expand any {a,b,c} groups as described in ExpandBraces
 //	replace any items that start with a module with the actual location on disk
 //	expand any glob patterns
 //	remove duplicates
@@ -42,36 +88,59 @@ func SplitList(s string) (list []string) {
 //
 // modules is the list of modules returned from ModulePaths.
 //
-// Glob patterns will be matched, and if nothing is found, no file will be generated.
-// However, if a path does not have a glob pattern, but does not exist, it will be left in the list,
+// Glob patterns will be matched as described in Glob, so a ** element matches any number of directories.
+// If nothing is found, no file will be generated. However, if a path does not have a glob pattern, but does not exist, it will be left in the list,
 // since it might refer to a file or directory you want to add.
-// The list out is not necessarily in the same order as the list in.
+// The list out is in the same order as the list in, with the matches of each glob pattern sorted, and the
+// patterns that braces expand to kept in order, so "web/{app,lib}/*.js" lists the files in app before those in lib. If an item
+// appears more than once, only the first one is kept.
+//
+// Malformed glob patterns are treated as patterns that match nothing. Use ModuleExpandFileListEx to detect them.
 func ModuleExpandFileList(args []string, modules map[string]string) (list []string) {
-	files2 := make(map[string]bool)
+	list, _, _ = ModuleExpandFileListEx(args, modules)
+	return
+}
+
+// ModuleExpandFileListEx is like ModuleExpandFileList, but also returns the arguments that are glob patterns
+// that did not match anything, in the order they were given. If an argument is a malformed glob pattern,
+// it returns a *PatternError that names the argument.
+func ModuleExpandFileListEx(args []string, modules map[string]string) (list []string, unmatched []string, err error) {
+	seen := make(map[string]bool)
 
 	for _, arg := range args {
-		arg = os.ExpandEnv(arg)
-		arg, _ = GetModulePath(arg, modules)
-		arg = filepath.FromSlash(arg)
 		var files []string
-		if HasMeta(arg) {
-			files, _ = filepath.Glob(arg)
-		} else {
-			files = append(files, arg)
+		var globbed bool
+		for _, alt := range ExpandBraces(os.ExpandEnv(arg)) {
+			expanded, _ := GetModulePath(alt, modules)
+			expanded = filepath.FromSlash(expanded)
+			if HasMeta(expanded) {
+				globbed = true
+				var matches []string
+				matches, err = Glob(expanded)
+				if err != nil {
+					return nil, nil, &PatternError{Pattern: arg, Err: err}
+				}
+				files = append(files, matches...)
+			} else {
+				files = append(files, expanded)
+			}
+		}
+		if globbed && len(files) == 0 {
+			unmatched = append(unmatched, arg)
 		}
 
 		for _, f := range files {
-			files2[f] = true
+			if !seen[f] {
+				seen[f] = true
+				list = append(list, f)
+			}
 		}
 	}
-	for k := range files2 {
-		list = append(list, k)
-	}
 	return
 }
 
 // HasMeta reports whether path contains any of the magic characters
-// recognized by Match.
+// recognized by Match, including the ** element recognized by Glob.
 // This is copied from the unexported function from filepath.
 func HasMeta(path string) bool {
 	magicChars := `*?[`
@@ -109,81 +178,11 @@ const (
 // will be created. If a directory is over-writing another directory, this will determine what happens when
 // file names are duplicates. Note that old files in a directory will not be deleted when a directory
 // overwrites another directory. If you want old files to be deleted, empty the destination directory first.
+//
+// CopyFiles is the same as calling Copy with the overwrite option. Use Copy to be able to cancel the
+// operation and report progress.
 func CopyFiles(dst string, overwrite CopyOverwriteType, src ...string) (err error) {
-	// Sanity checks
-	if dst == "" {
-		return fmt.Errorf("no destination specified")
-	}
-
-	if len(src) == 0 || src[0] == "" {
-		return fmt.Errorf("no source files specified")
-	}
-
-	dstInfo, destErr := os.Stat(dst)
-	srcInfo, srcErr := os.Stat(src[0])
-
-	if srcErr != nil {
-		return fmt.Errorf("error with source: %s", srcErr.Error())
-	}
-
-	if len(src) > 1 || srcInfo.IsDir() {
-		if destErr != nil {
-			return destErr // path doesn't exist?
-		}
-		if !dstInfo.IsDir() {
-			return fmt.Errorf("when copying multiple files, the destination must be a directory: %s", dst)
-		}
-
-		for _, f := range src {
-			err = copyTo(f, dst, "", overwrite)
-			if err != nil {
-				return
-			}
-		}
-	} else {
-		if os.IsPathSeparator(dst[len(dst)-1]) {
-			// Definitely trying to point to a directory
-			if os.IsNotExist(destErr) {
-				return fmt.Errorf("the destination directory does not exist: %s", dst)
-			}
-			err = copyTo(src[0], dst, "", overwrite)
-			if err != nil {
-				return
-			}
-		} else {
-			// might be a destination directory, or a file
-			if os.IsNotExist(destErr) {
-				// Since it doesn't exist, we are going to assume we are trying to specify a file, since
-				// we have already checked above to see if we are trying to specify a directory with a slash at end.
-
-				// Check on parent directory
-				parentDir, fileName := filepath.Split(dst)
-				_, parentErr := os.Stat(parentDir)
-				if parentErr != nil {
-					return fmt.Errorf("the parent directory of a new file must exist: %s", dst)
-				}
-				// We are writing to a new file
-				err = copyFileTo(src[0], parentDir, fileName, overwrite)
-				if err != nil {
-					return
-				}
-			} else {
-				// destination is a file or a directory that already exists
-				if dstInfo.IsDir() {
-					err = copyTo(src[0], dst, "", overwrite)
-					if err != nil {
-						return
-					}
-				} else {
-					parentDir, fileName := filepath.Split(dst)
-					err = copyTo(src[0], parentDir, fileName, overwrite)
-					if err != nil {
-						return
-					}
-				}
-			}
-		}
-	}
+	_, err = Copy(context.Background(), dst, src, CopyOptions{Overwrite: overwrite})
 	return
 }
 
@@ -193,162 +192,20 @@ func CopyFiles(dst string, overwrite CopyOverwriteType, src ...string) (err erro
 // It does no checks to see if the destination directory exists.
 // overwrite would prevent the file from being copied, then the copy does not happen and
 // error is nil.
-//
-// Successful copy operations are logged to the default logger.
 func copyFileTo(src string, destDir string, name string, overwrite CopyOverwriteType) error {
-	srcInfo, srcErr := os.Stat(src)
-	if srcErr != nil {
-		return srcErr
-	}
-	if srcInfo.IsDir() {
-		return fmt.Errorf(`source "%s"" is not a file`, src)
-	}
-	var perm os.FileMode
-
-	perm = srcInfo.Mode() & os.ModePerm
-
-	if name == "" {
-		name = filepath.Base(src)
-	}
-	destName := filepath.Join(destDir, name)
-	destInfo, destErr := os.Stat(destName)
-	if destErr == nil {
-		// destination exists
-		if overwrite == CopyDoNotOverwrite {
-			return nil
-		} else if overwrite == CopyOverwriteOnlyIfNewer {
-			modSrc := srcInfo.ModTime()
-			modDest := destInfo.ModTime()
-
-			if modSrc.Before(modDest) || modSrc.Equal(modDest) {
-				return nil
-			}
-		}
-		// prepare for copy by deleting in case permissions are different
-		if err := os.Remove(destName); err != nil {
-			return err
-		}
-	}
-
-	from, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = from.Close()
-	}()
-
-	to, err := os.OpenFile(destName, os.O_RDWR|os.O_CREATE, perm)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		_ = to.Close()
-	}()
-
-	_, err = io.Copy(to, from)
-	if err != nil {
-		return err
-	}
-
-	err = to.Close()
-	if err != nil {
-		return err
-	}
-
-	log.Printf("Copied %s to %s\n", src, destName)
-
-	return nil
-}
-
-// copyTo copies the src to the destination directory. The source can be a file or directory.
-// if a name is specified, src must be a file. The name will be the name of the file in the new directory.
-func copyTo(src string, destDir string, name string, overwrite CopyOverwriteType) error {
-	srcInfo, srcErr := os.Stat(src)
-
-	if srcErr != nil {
-		return srcErr
-	}
-
-	if srcInfo.IsDir() && name != "" {
-		if name != filepath.Base(src) {
-			return fmt.Errorf("cannot copy a directory to a file")
-		}
-		p := filepath.Join(destDir, name)
-		dstInfo, dstErr := os.Stat(p)
-		if (dstErr == nil || !os.IsNotExist(dstErr)) &&
-			!dstInfo.IsDir() {
-			return fmt.Errorf("cannot copy a directory onto a file that already exists: %s", p)
-		}
-	}
-
-	if !srcInfo.IsDir() {
-		return copyFileTo(src, destDir, name, overwrite)
-	}
-
-	// src is a directory, and destination is a directory
-	return CopyDirectory(src, destDir, overwrite)
+	c := copier{ctx: context.Background(), opts: CopyOptions{Overwrite: overwrite}}
+	return c.copyFile(src, destDir, name)
 }
 
 // CopyDirectory copies the src directory to the destination directory. The destination directory will be the parent of
 // the resulting directory, and the result will have the same name as the source. If the destination already exists,
 // it will perform a kind of merge, where existing files will not be touched, and only new files will be copied.
 // If you want to replace the destination, delete it first. dst must exist.
+//
+// CopyDirectory is the same as calling Copy with src as the only source and the overwrite option. Use
+// CopyDirectoryContext to be able to cancel the operation and report progress.
 func CopyDirectory(src, dst string, overwrite CopyOverwriteType) (err error) {
-	dstInfo, dstErr := os.Stat(dst)
-	_, srcErr := os.Stat(src)
-
-	if srcErr != nil {
-		return fmt.Errorf("source directory error: %s", srcErr.Error())
-	}
-
-	if dstErr != nil {
-		return fmt.Errorf("destination directory error: %s", dstErr.Error())
-	}
-
-	dirDest := filepath.Dir(dst)
-	if len(src) <= len(dirDest) && dirDest[:len(src)] == src { // does dst start with src?
-		return fmt.Errorf("destination directory is not allowed to be in the src directory")
-	}
-
-	if !dstInfo.Mode().IsDir() {
-		return fmt.Errorf("source %s is a file, not a directory", dst)
-	}
-
-	// create destination if needed
-	newPath := filepath.Join(dst, filepath.Base(src))
-
-	newInfo, err := os.Stat(newPath)
-	if err == nil || !os.IsNotExist(err) {
-		// path exists
-		if !newInfo.IsDir() {
-			return fmt.Errorf("path %s is a directory in the source, but %s is a file in the destination", src, newPath)
-		}
-	} else {
-		//perm := srcInfo.Mode().Perm()	// copy the permission
-		err = os.Mkdir(newPath, 0755)
-		if err != nil {
-			return fmt.Errorf("error creating directory %s: %s", newPath, err.Error())
-		}
-	}
-
-	f, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	list, err := f.Readdir(-1)
-	_ = f.Close()
-
-	for _, item := range list {
-		itemName := item.Name()
-		itemPath := filepath.Join(src, itemName)
-		err = copyTo(itemPath, newPath, itemName, overwrite)
-		if err != nil {
-			return err
-		}
-	}
-
+	_, err = Copy(context.Background(), dst, []string{src}, CopyOptions{Overwrite: overwrite})
 	return
 }
 
@@ -358,99 +215,18 @@ func CopyDirectory(src, dst string, overwrite CopyOverwriteType) (err error) {
 // the resulting directory, and the result will have the same name as the source. If the destination already exists,
 // it will perform a kind of merge, where existing files will not be touched, and only new files will be copied.
 // If you want to replace the destination, delete it first. dst must exist.
+//
+// The excludes are matched as described in Matcher, so a later pattern that starts with ! brings back
+// files that an earlier pattern excluded. Hidden files are copied, and ignore files are not read.
+//
+// Use CopyDirectoryContext to be able to cancel the operation and report progress, to only copy files that
+// match include patterns, to skip the files listed in ignore files by setting CopyOptions.IgnoreFiles, or to skip
+// hidden files by setting CopyOptions.Hidden.
 func CopyDirectoryEx(src, dst string, overwrite CopyOverwriteType, excludes []string) (err error) {
-	dstInfo, dstErr := os.Stat(dst)
-	_, srcErr := os.Stat(src)
-
-	if srcErr != nil {
-		return fmt.Errorf("source directory error: %s", srcErr.Error())
-	}
-
-	if dstErr != nil {
-		return fmt.Errorf("destination directory error: %s", dstErr.Error())
-	}
-
-	dirDest := filepath.Dir(dst)
-	if len(src) <= len(dirDest) && dirDest[:len(src)] == src { // does dst start with src?
-		return fmt.Errorf("destination directory is not allowed to be in the src directory")
-	}
-
-	if !dstInfo.Mode().IsDir() {
-		return fmt.Errorf("source %s is a file, not a directory", dst)
-	}
-
-	// create destination if needed
-	newPath := filepath.Join(dst, filepath.Base(src))
-
-	newInfo, err := os.Stat(newPath)
-	if err == nil || !os.IsNotExist(err) {
-		// path exists
-		if !newInfo.IsDir() {
-			return fmt.Errorf("path %s is a directory in the source, but %s is a file in the destination", src, newPath)
-		}
-	} else {
-		//perm := srcInfo.Mode().Perm()	// copy the permission
-		err = os.Mkdir(newPath, 0755)
-		if err != nil {
-			return fmt.Errorf("error creating directory %s: %s", newPath, err.Error())
-		}
-	}
-
-	f, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	list, err := f.Readdir(-1)
-	_ = f.Close()
-
-	for _, item := range list {
-		itemName := item.Name()
-		if isExcluded(itemName, excludes) {
-			continue // skip
-		}
-
-		itemPath := filepath.Join(src, itemName)
-		err = copyToEx(itemPath, newPath, itemName, overwrite, excludes)
-		if err != nil {
-			return err
-		}
-	}
-
+	_, err = CopyDirectoryContext(context.Background(), src, dst, CopyOptions{Overwrite: overwrite, Excludes: excludes})
 	return
 }
 
-// copyToEx copies the src to the destination directory. The source can be a file or directory.
-// if a name is specified, src must be a file. The name will be the name of the file in the new directory.
-func copyToEx(src string, destDir string, name string, overwrite CopyOverwriteType, excludes []string) error {
-	srcInfo, srcErr := os.Stat(src)
-
-	if srcErr != nil {
-		return srcErr
-	}
-
-	if srcInfo.IsDir() && name != "" {
-		if name != filepath.Base(src) {
-			return fmt.Errorf("cannot copy a directory to a file")
-		}
-		p := filepath.Join(destDir, name)
-		dstInfo, dstErr := os.Stat(p)
-		if (dstErr == nil || !os.IsNotExist(dstErr)) &&
-			!dstInfo.IsDir() {
-			return fmt.Errorf("cannot copy a directory onto a file that already exists: %s", p)
-		}
-	}
-
-	if !srcInfo.IsDir() {
-		if isExcluded(src, excludes) {
-			return nil // skip file
-		}
-		return copyFileTo(src, destDir, name, overwrite)
-	}
-
-	// src is a directory, and destination is a directory
-	return CopyDirectoryEx(src, destDir, overwrite, excludes)
-}
-
 // CopyFilesEx copies the src files or directories to the destination excluding files matching the exclusions slice.
 //
 // If there is more than one source, the destination must be a directory that exists. The items listed
@@ -466,87 +242,11 @@ func copyToEx(src string, destDir string, name string, overwrite CopyOverwriteTy
 // will be created. If a directory is over-writing another directory, this will determine what happens when
 // file names are duplicates. Note that old files in a directory will not be deleted when a directory
 // overwrites another directory. If you want old files to be deleted, empty the destination directory first.
+//
+// CopyFilesEx is the same as calling Copy with the overwrite and exclusions options. Use Copy to be able to
+// cancel the operation and report progress.
 func CopyFilesEx(dst string, overwrite CopyOverwriteType, exclusions []string, src ...string) (err error) {
-	// Sanity checks
-	if dst == "" {
-		return fmt.Errorf("no destination specified")
-	}
-
-	if len(src) == 0 {
-		return fmt.Errorf("no source files specified")
-	}
-
-	dstInfo, destErr := os.Stat(dst)
-	srcInfo, srcErr := os.Stat(src[0])
-
-	if srcErr != nil {
-		return fmt.Errorf("error with source: %s", srcErr.Error())
-	}
-
-	if len(src) > 1 || srcInfo.IsDir() {
-		if destErr != nil {
-			return destErr // path doesn't exist?
-		}
-		if !dstInfo.IsDir() {
-			return fmt.Errorf("when copying multiple files, the destination must be a directory: %s", dst)
-		}
-
-		for _, f := range src {
-			err = copyToEx(f, dst, "", overwrite, exclusions)
-			if err != nil {
-				return
-			}
-		}
-	} else {
-		if os.IsPathSeparator(dst[len(dst)-1]) {
-			// Definitely trying to point to a directory
-			if os.IsNotExist(destErr) {
-				return fmt.Errorf("the destination directory does not exist: %s", dst)
-			}
-			err = copyToEx(src[0], dst, "", overwrite, exclusions)
-			if err != nil {
-				return
-			}
-		} else {
-			// might be a destination directory, or a file
-			if os.IsNotExist(destErr) {
-				// Since it doesn't exist, we are going to assume we are trying to specify a file, since
-				// we have already checked above to see if we are trying to specify a directory with a slash at end.
-
-				// Check on parent directory
-				parentDir, fileName := filepath.Split(dst)
-				_, parentErr := os.Stat(parentDir)
-				if parentErr != nil {
-					return fmt.Errorf("the parent directory of a new file must exist: %s", dst)
-				}
-				// We are writing to a new file
-				if isExcluded(src[0], exclusions) {
-					return nil
-				}
-				err = copyFileTo(src[0], parentDir, fileName, overwrite)
-				if err != nil {
-					return
-				}
-			} else {
-				// destination is a file or a directory that already exists
-				if dstInfo.IsDir() {
-					err = copyToEx(src[0], dst, "", overwrite, exclusions)
-					if err != nil {
-						return
-					}
-				} else {
-					if isExcluded(src[0], exclusions) {
-						return nil
-					}
-					parentDir, fileName := filepath.Split(dst)
-					err = copyTo(src[0], parentDir, fileName, overwrite)
-					if err != nil {
-						return
-					}
-				}
-			}
-		}
-	}
+	_, err = Copy(context.Background(), dst, src, CopyOptions{Overwrite: overwrite, Excludes: exclusions})
 	return
 }
 
@@ -560,14 +260,3 @@ func IsDir(path string) bool {
 	}
 	return false
 }
-
-// isExcluded returns true if the given file matches one of the exclusion strings
-func isExcluded(file string, excludes []string) bool {
-	for _, e := range excludes {
-		m, _ := filepath.Match(e, filepath.Base(file))
-		if m {
-			return true
-		}
-	}
-	return false
-}
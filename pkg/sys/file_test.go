@@ -5,8 +5,11 @@
 package sys
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -25,6 +28,21 @@ func makeTempDir() (dir string, err error) {
 	return
 }
 
+// writeTree writes the files, keyed by their slash separated paths relative to root, creating the directories
+// they are in.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func testDataDir1() string {
 	return filepath.Join("testdata", "dir1")
 }
@@ -280,6 +298,24 @@ func TestSplitList(t *testing.T) {
 
 }
 
+func TestReadFileList(t *testing.T) {
+	l, err := ReadFileList(strings.NewReader("a.txt\r\n\nb c.txt\n$HOME/d\n"), false)
+	if err != nil || !reflect.DeepEqual(l, []string{"a.txt", "b c.txt", "$HOME/d"}) {
+		t.Errorf("Got %v, %v", l, err)
+	}
+	l, err = ReadFileList(strings.NewReader("a\nb\x00c\x00"), true)
+	if err != nil || !reflect.DeepEqual(l, []string{"a\nb", "c"}) {
+		t.Errorf("Got %v, %v", l, err)
+	}
+}
+
+func TestSplitPatterns(t *testing.T) {
+	l := SplitPatterns("a:*.{b,c};re:^x:y$;!re:z:w")
+	if !reflect.DeepEqual(l, []string{"a", "*.{b,c}", "re:^x:y$", "!re:z:w"}) {
+		t.Errorf("List is not split correctly: %v", l)
+	}
+}
+
 func TestDirectoryCopyEx(t *testing.T) {
 	dir1 := filepath.Join(os.TempDir(), "dir1")
 	dir2 := filepath.Join(os.TempDir(), "dir2")
@@ -363,7 +399,7 @@ func TestModuleExpandFileList(t *testing.T) {
 	l := ModuleExpandFileList(fileList, modules)
 
 	if len(l) != 3 {
-		t.Error("Not the correct list size.")
+		t.Fatal("Not the correct list size.")
 	}
 
 	for _, i := range l {
@@ -372,18 +408,37 @@ func TestModuleExpandFileList(t *testing.T) {
 		}
 	}
 
-	if !listContains(l, filepath.FromSlash("/a/b/c")) {
+	if filepath.Base(l[0]) != "README.md" || filepath.Base(l[1]) != "LICENSE" {
+		t.Error("Items are not in order.")
+	}
+
+	if l[2] != filepath.FromSlash("/a/b/c") {
 		t.Error("Item 3 was changed.")
 	}
 }
 
-func listContains(list []string, val string) bool {
-	for _, l := range list {
-		if l == val {
-			return true
-		}
+func TestModuleExpandFileListEx(t *testing.T) {
+	fileList := []string{
+		"testdata/t*",
+		"testdata/*.none",
+		"testdata/t1.txt", // duplicate
+		"testdata/*.abc",
+	}
+	l, unmatched, err := ModuleExpandFileListEx(fileList, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range l {
+		got = append(got, filepath.ToSlash(f))
+	}
+	expected := []string{"testdata/t1.txt", "testdata/t2.txt", "testdata/t3.abc"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v", got)
+	}
+	if !reflect.DeepEqual(unmatched, []string{"testdata/*.none"}) {
+		t.Errorf("Got unmatched %v", unmatched)
 	}
-	return false
 }
 
 func Test_copyFileTo(t *testing.T) {
@@ -630,3 +685,64 @@ func TestCopyFilesEx(t *testing.T) {
 	})
 
 }
+
+func TestCopyFilesDryRun(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err = Copy(context.Background(), tempDir, []string{testDataDir1()}, CopyOptions{Overwrite: CopyOverwrite, DryRun: true}); err != nil {
+		t.Errorf("Copy() error = %v", err)
+	}
+	items, _ := os.ReadDir(tempDir)
+	if len(items) != 0 {
+		t.Errorf("Copy() dry run copied %d items", len(items))
+	}
+}
+
+type testLogger struct {
+	debug []string
+	info  []string
+}
+
+func (l *testLogger) Debug(msg string, _ ...any) {
+	l.debug = append(l.debug, msg)
+}
+
+func (l *testLogger) Info(msg string, _ ...any) {
+	l.info = append(l.info, msg)
+}
+
+func TestCopyFilesLogger(t *testing.T) {
+	tempDir, err := makeTempDir()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer os.RemoveAll(tempDir)
+
+	l := new(testLogger)
+	opts := CopyOptions{Excludes: []string{"*.abc"}, Logger: l}
+	if _, err = Copy(context.Background(), tempDir, []string{filepath.Join(testDataDir1(), "a")}, opts); err != nil {
+		t.Fatal(err)
+	}
+	// the directory and two files
+	if len(l.info) != 3 {
+		t.Errorf("Wrong number of info messages: %v", l.info)
+	}
+	if len(l.debug) != 1 || l.debug[0] != "Skipped "+filepath.Join(testDataDir1(), "a", "t3.abc")+": excluded" {
+		t.Errorf("Wrong debug messages: %v", l.debug)
+	}
+
+	l = new(testLogger)
+	opts.Logger = l
+	if _, err = Copy(context.Background(), tempDir, []string{filepath.Join(testDataDir1(), "a")}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.info) != 0 || len(l.debug) != 3 {
+		t.Errorf("Existing files were not skipped: %v %v", l.info, l.debug)
+	}
+}
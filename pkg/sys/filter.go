@@ -0,0 +1,158 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter selects files by their type, size and modification time, and limits how deep directories are searched.
+// It is the part of a Matcher that looks at the files themselves rather than their names.
+//
+// The zero value selects everything, as does a nil *Filter.
+type Filter struct {
+	// Types lists the types selected, using f for regular files, d for directories and l for symbolic links,
+	// like "f" or "fl". If empty, all types are selected.
+	Types string
+	// MinSize, if not zero, is the size in bytes that a file must have at least. Directories are not compared
+	// against MinSize and MaxSize.
+	MinSize int64
+	// MaxSize, if not zero, is the size in bytes that a file must have at most.
+	MaxSize int64
+	// OlderThan, if not zero, selects only what was modified before it.
+	OlderThan time.Time
+	// NewerThan, if not zero, selects only what was modified after it.
+	NewerThan time.Time
+	// MaxDepth, if greater than zero, limits how deep directories are searched. The items in a directory that is
+	// being searched are at depth 1, the items in its subdirectories are at depth 2, and so on.
+	MaxDepth int
+}
+
+// Validate returns an error if Types has a letter other than f, d or l.
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, t := range f.Types {
+		if !strings.ContainsRune("fdl", t) {
+			return fmt.Errorf("invalid file type %q, use f, d or l", t)
+		}
+	}
+	return nil
+}
+
+// Match returns true if the file or directory described by info, which is at the given depth, is selected.
+// The info should come from os.Lstat, or from a function like it that does not follow symbolic links,
+// so that the links can be selected by type.
+func (f *Filter) Match(info fs.FileInfo, depth int) bool {
+	if f == nil {
+		return true
+	}
+	if !f.inDepth(depth) {
+		return false
+	}
+	if f.Types != "" && !strings.ContainsRune(f.Types, fileType(info.Mode())) {
+		return false
+	}
+	if !info.IsDir() {
+		if f.MinSize != 0 && info.Size() < f.MinSize {
+			return false
+		}
+		if f.MaxSize != 0 && info.Size() > f.MaxSize {
+			return false
+		}
+	}
+	if !f.OlderThan.IsZero() && !info.ModTime().Before(f.OlderThan) {
+		return false
+	}
+	if !f.NewerThan.IsZero() && !info.ModTime().After(f.NewerThan) {
+		return false
+	}
+	return true
+}
+
+// MatchFile returns true if the file or directory at p is selected. p is at depth 0, since it is given directly
+// rather than found in a directory. A path that does not exist is selected, so that it can be reported or created.
+func (f *Filter) MatchFile(p string) bool {
+	if f == nil {
+		return true
+	}
+	info, err := os.Lstat(p)
+	if err != nil {
+		return true
+	}
+	return f.Match(info, 0)
+}
+
+// inDepth returns true if an item at the given depth is not deeper than MaxDepth.
+func (f *Filter) inDepth(depth int) bool {
+	return f == nil || f.MaxDepth <= 0 || depth <= f.MaxDepth
+}
+
+// descend returns true if the items in a directory at the given depth should be searched.
+func (f *Filter) descend(depth int) bool {
+	return f.inDepth(depth + 1)
+}
+
+// fileType returns the letter that Filter.Types uses for the type in mode.
+func fileType(mode fs.FileMode) rune {
+	switch {
+	case mode&fs.ModeSymlink != 0:
+		return 'l'
+	case mode.IsDir():
+		return 'd'
+	case mode.IsRegular():
+		return 'f'
+	}
+	return '?'
+}
+
+// depth returns the depth of a slash or native separated path relative to a root directory, which is the
+// number of elements in it.
+func depth(rel string) int {
+	return len(splitPath(rel))
+}
+
+// ParseSize parses a size in bytes, like "1500", "10k", "1.5M" or "2GB". The suffixes k, m, g and t, in either case and
+// optionally followed by b or ib, multiply by 1024, 1024², 1024³ and 1024⁴.
+func ParseSize(s string) (int64, error) {
+	num := strings.TrimSpace(strings.ToLower(s))
+	num = strings.TrimSuffix(strings.TrimSuffix(num, "b"), "i")
+	mult := int64(1)
+	if num != "" {
+		if i := strings.IndexByte("kmgt", num[len(num)-1]); i >= 0 {
+			mult = int64(1) << (10 * (i + 1))
+			num = num[:len(num)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// ParseDuration parses a duration as described in time.ParseDuration, and also accepts the units d for days
+// and w for weeks, so "7d" and "1w" are both a week. A number of days or weeks must be an integer,
+// and cannot be combined with other units.
+func ParseDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if s != "" {
+		if u, ok := unit[s[len(s)-1]]; ok {
+			if n, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+				return time.Duration(n) * u, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid duration %q", s)
+}
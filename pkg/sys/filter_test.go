@@ -0,0 +1,140 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeFilterTree makes a tree of files with different sizes, with old.txt modified a week ago.
+func makeFilterTree(t *testing.T) string {
+	root := filepath.Join(t.TempDir(), "tree")
+	writeTree(t, root, map[string]string{
+		"old.txt":      strings.Repeat("x", 10),
+		"small.txt":    strings.Repeat("x", 10),
+		"big.txt":      strings.Repeat("x", 5000),
+		"a/mid.txt":    strings.Repeat("x", 100),
+		"a/b/deep.txt": strings.Repeat("x", 100),
+	})
+	week := time.Now().Add(-7 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "old.txt"), week, week); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestFilter(t *testing.T) {
+	root := makeFilterTree(t)
+	day := time.Now().Add(-24 * time.Hour)
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"none", Filter{}, []string{"a/b/deep.txt", "a/mid.txt", "big.txt", "old.txt", "small.txt"}},
+		{"min size", Filter{MinSize: 100}, []string{"a/b/deep.txt", "a/mid.txt", "big.txt"}},
+		{"max size", Filter{MaxSize: 100}, []string{"a/b/deep.txt", "a/mid.txt", "old.txt", "small.txt"}},
+		{"older", Filter{OlderThan: day}, []string{"old.txt"}},
+		{"newer", Filter{NewerThan: day, MaxSize: 10}, []string{"small.txt"}},
+		{"depth", Filter{MaxDepth: 2}, []string{"a/mid.txt", "big.txt", "old.txt", "small.txt"}},
+		{"type", Filter{Types: "d"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ExpandDirs([]string{root}, &Matcher{Filter: &tt.filter})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := relFiles(root, files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	f := &Filter{Types: "d"}
+	if !f.MatchFile(filepath.Join(root, "a")) || f.MatchFile(filepath.Join(root, "big.txt")) {
+		t.Error("Type filter did not work")
+	}
+	if (&Filter{Types: "x"}).Validate() == nil {
+		t.Error("Expected an invalid type error")
+	}
+}
+
+func TestCopyFilter(t *testing.T) {
+	root := makeFilterTree(t)
+	dst := t.TempDir()
+	_, err := Copy(context.Background(), dst, []string{root}, CopyOptions{Filter: &Filter{MaxSize: 100, MaxDepth: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for f, want := range map[string]bool{
+		"tree/small.txt":    true,
+		"tree/old.txt":      true,
+		"tree/big.txt":      false,
+		"tree/a":            true,
+		"tree/a/mid.txt":    false,
+		"tree/a/b/deep.txt": false,
+	} {
+		if _, err := os.Stat(filepath.Join(dst, filepath.FromSlash(f))); (err == nil) != want {
+			t.Errorf("%s copied: %v, want %v", f, err == nil, want)
+		}
+	}
+
+	fsys := NewMemFS()
+	_, err = CopyFS(os.DirFS(root), ".", ".", CopyOptions{Filter: &Filter{MinSize: 100, MaxDepth: 2}, Dest: fsys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for f, want := range map[string]bool{
+		"big.txt":      true,
+		"small.txt":    false,
+		"a/mid.txt":    true,
+		"a/b":          true,
+		"a/b/deep.txt": false,
+	} {
+		if _, err := fsys.Stat(f); (err == nil) != want {
+			t.Errorf("%s copied: %v, want %v", f, err == nil, want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for s, want := range map[string]int64{
+		"1500": 1500,
+		"10k":  10240,
+		"10KB": 10240,
+		"1.5M": 1572864,
+		"2GiB": 2 << 30,
+	} {
+		if got, err := ParseSize(s); err != nil || got != want {
+			t.Errorf("ParseSize(%s) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	if _, err := ParseSize("big"); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"36h":  36 * time.Hour,
+		"7d":   7 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"1h5m": time.Hour + 5*time.Minute,
+	} {
+		if got, err := ParseDuration(s); err != nil || got != want {
+			t.Errorf("ParseDuration(%s) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParseDuration("1.5d"); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// doubleStar is the path element that matches any number of directories.
+const doubleStar = "**"
+
+// HasDoubleStar reports whether pattern contains a ** element, which matches any number of directories.
+func HasDoubleStar(pattern string) bool {
+	for _, elem := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if elem == doubleStar {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether name matches the pattern. The pattern and name are divided into elements by
+// slashes, and each element of the pattern is matched against an element of the name as described in path.Match.
+// A pattern element of ** matches zero or more elements of the name, so "web/**/*.js" matches "web/app.js" and
+// "web/lib/ui/menu.js".
+//
+// Both slashes and the native path separator are accepted in pattern and name.
+// The only possible returned error is filepath.ErrBadPattern, when pattern is malformed.
+func MatchPath(pattern, name string) (bool, error) {
+	patterns := splitPath(pattern)
+	for _, p := range patterns {
+		if p != doubleStar {
+			if _, err := path.Match(p, ""); err != nil {
+				return false, filepath.ErrBadPattern
+			}
+		}
+	}
+	return matchElements(patterns, splitPath(name)), nil
+}
+
+// splitPath divides p into its slash separated elements. An empty path has no elements.
+func splitPath(p string) []string {
+	p = filepath.ToSlash(p)
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// matchElements matches the elements of a pattern against the elements of a name.
+// The patterns must already be known to be valid.
+func matchElements(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		p := patterns[0]
+		if p == doubleStar {
+			// skip repeated ** elements, then try the rest of the pattern at every position
+			for len(patterns) > 0 && patterns[0] == doubleStar {
+				patterns = patterns[1:]
+			}
+			if len(patterns) == 0 {
+				return true
+			}
+			for i := 0; i <= len(names); i++ {
+				if matchElements(patterns, names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(p, names[0]); !ok {
+			return false
+		}
+		patterns = patterns[1:]
+		names = names[1:]
+	}
+	return len(names) == 0
+}
+
+// Glob returns the names of all files matching pattern, or nil if there is no matching file. It is like
+// filepath.Glob, but a ** element of the pattern matches any number of directories, as described in MatchPath.
+// The directory that a ** starts searching from is not itself a match, so "web/**" matches everything inside
+// the web directory, but not web. The results are sorted.
+//
+// The only possible returned error is filepath.ErrBadPattern, when pattern is malformed.
+func Glob(pattern string) (matches []string, err error) {
+	if !HasDoubleStar(pattern) {
+		matches, err = filepath.Glob(pattern)
+		sort.Strings(matches)
+		return
+	}
+	if _, err = MatchPath(pattern, ""); err != nil {
+		return
+	}
+
+	// Divide the pattern at the first ** into the directories to search and the pattern to match inside them.
+	elems := strings.Split(filepath.ToSlash(pattern), "/")
+	i := 0
+	for elems[i] != doubleStar {
+		i++
+	}
+	rest := elems[i:]
+	root := filepath.FromSlash(strings.Join(elems[:i], "/"))
+	if i == 0 {
+		root = "."
+	} else if root == "" {
+		// the pattern starts with a slash
+		root = string(filepath.Separator)
+	}
+
+	var roots []string
+	if HasMeta(root) {
+		roots, _ = filepath.Glob(root)
+	} else {
+		roots = []string{root}
+	}
+
+	for _, r := range roots {
+		_ = filepath.WalkDir(r, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // skip what we cannot read, like filepath.Glob does
+			}
+			if p == r {
+				return nil
+			}
+			rel, _ := filepath.Rel(r, p)
+			if matchElements(rest, splitPath(rel)) {
+				if i == 0 {
+					// do not add a ./ prefix to relative patterns
+					p = rel
+				}
+				matches = append(matches, p)
+			}
+			return nil
+		})
+	}
+	sort.Strings(matches)
+	return
+}
+
+// ExpandBraces returns the patterns that result from expanding each {a,b,c} group in pattern, in order.
+// Groups can be nested, so "web/{*.js,css/{a,b}.css}" expands to "web/*.js", "web/css/a.css" and "web/css/b.css".
+// A group without a comma, or a brace without a match, is left as is.
+func ExpandBraces(pattern string) []string {
+	start, end, alts := findBraces(pattern)
+	if start < 0 {
+		return []string{pattern}
+	}
+	var out []string
+	for _, alt := range alts {
+		out = append(out, ExpandBraces(pattern[:start]+alt+pattern[end+1:])...)
+	}
+	return out
+}
+
+// findBraces finds the first brace group that has a comma in it, and returns the positions of its braces and
+// its comma separated alternatives. start is -1 if there is no such group.
+func findBraces(pattern string) (start, end int, alts []string) {
+	escapes := runtime.GOOS != "windows"
+	for start = 0; start < len(pattern); start++ {
+		if escapes && pattern[start] == '\\' {
+			start++
+			continue
+		}
+		if pattern[start] != '{' {
+			continue
+		}
+		depth := 0
+		last := start + 1
+		for end = start + 1; end < len(pattern); end++ {
+			switch c := pattern[end]; {
+			case escapes && c == '\\':
+				end++
+			case c == '{':
+				depth++
+			case c == '}' && depth > 0:
+				depth--
+			case c == ',' && depth == 0:
+				alts = append(alts, pattern[last:end])
+				last = end + 1
+			case c == '}':
+				if alts != nil {
+					alts = append(alts, pattern[last:end])
+					return
+				}
+				// no comma, so keep looking after this brace
+				end = len(pattern)
+			}
+		}
+		alts = nil
+	}
+	return -1, -1, nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"web/**/*.js", "web/app.js", true},
+		{"web/**/*.js", "web/lib/ui/menu.js", true},
+		{"web/**/*.js", "web/lib/ui/menu.css", false},
+		{"web/**/*.js", "other/app.js", false},
+		{"**/*.tmp", "a.tmp", true},
+		{"**/*.tmp", "a/b/c.tmp", true},
+		{"web/**", "web/a/b", true},
+		{"**/dev/**", "web/dev/a.js", true},
+		{"**/dev/**", "web/devel/a.js", false},
+		{"a/*/c", "a/b/b/c", false},
+	}
+	for _, tt := range tests {
+		got, err := MatchPath(tt.pattern, filepath.FromSlash(tt.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("MatchPath(%s, %s) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := MatchPath("**/[a", "a"); !errors.Is(err, filepath.ErrBadPattern) {
+		t.Error("Expected a bad pattern error")
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"testdata/dir1/**/t1.txt", []string{"testdata/dir1/a/t1.txt", "testdata/dir1/b/t1.txt", "testdata/dir1/c/t1.txt"}},
+		{"testdata/**/*.abc", []string{"testdata/dir1/a/t3.abc", "testdata/dir1/c/e/no.abc", "testdata/t3.abc"}},
+		{"testdata/dir*/**/e", []string{"testdata/dir1/c/e"}},
+		{"testdata/dir2/**", []string{"testdata/dir2/d", "testdata/dir2/d/t4.txt"}},
+		{"testdata/*.abc", []string{"testdata/t3.abc"}},
+	}
+	for _, tt := range tests {
+		matches, err := Glob(filepath.FromSlash(tt.pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, m := range matches {
+			got = append(got, filepath.ToSlash(m))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Glob(%s) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestDoubleStarExclude(t *testing.T) {
+	m := NewMatcher(nil, []string{"c/**/*.abc", "**/b"})
+	files, err := ExpandFiles([]string{"testdata/dir1"}, nil, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.ToSlash(f))
+	}
+	expected := []string{
+		"testdata/dir1/a/t1.txt",
+		"testdata/dir1/a/t2.txt",
+		"testdata/dir1/a/t3.abc",
+		"testdata/dir1/c/e/yes.txt",
+		"testdata/dir1/c/t1.txt",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExpandFiles returned %v", got)
+	}
+}
+
+func TestModuleDoubleStar(t *testing.T) {
+	modules, err := ModulePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := ModuleExpandFileList([]string{"github.com/goradd/gofile/pkg/sys/testdata/**/no.abc"}, modules)
+	if len(l) != 1 || !strings.HasSuffix(filepath.ToSlash(l[0]), "/testdata/dir1/c/e/no.abc") {
+		t.Errorf("Got %v", l)
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"a.txt", []string{"a.txt"}},
+		{"*.{js,css}", []string{"*.js", "*.css"}},
+		{"web/{*.js,css/{a,b}.css}", []string{"web/*.js", "web/css/a.css", "web/css/b.css"}},
+		{"{a,b}{1,2}", []string{"a1", "a2", "b1", "b2"}},
+		{"{a}/{b,}", []string{"{a}/b", "{a}/"}},
+		{"{a,b", []string{"{a,b"}},
+	}
+	for _, tt := range tests {
+		if got := ExpandBraces(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExpandBraces(%s) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestBraceFileList(t *testing.T) {
+	l, unmatched, err := ModuleExpandFileListEx([]string{"testdata/{t3,t1}.*", "testdata/*.{none,nada}", "testdata/{x,y}.txt"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range l {
+		got = append(got, filepath.ToSlash(f))
+	}
+	expected := []string{"testdata/t3.abc", "testdata/t1.txt", "testdata/x.txt", "testdata/y.txt"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v", got)
+	}
+	if !reflect.DeepEqual(unmatched, []string{"testdata/*.{none,nada}"}) {
+		t.Errorf("Got unmatched %v", unmatched)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HiddenPolicy determines whether hidden files and directories are processed. A file or directory is hidden if its
+// name starts with a dot, like .git or .DS_Store, or on Windows, if it has the hidden attribute.
+type HiddenPolicy int
+
+const (
+	// HiddenInclude processes hidden files and directories like any others. It is the zero value, so it is
+	// the policy of the functions that do not take options, like CopyDirectoryEx.
+	HiddenInclude HiddenPolicy = iota
+	// HiddenExclude skips hidden files and directories, and does not search hidden directories.
+	HiddenExclude
+)
+
+// excludes returns true if the policy skips hidden files.
+func (p HiddenPolicy) excludes() bool {
+	return p == HiddenExclude
+}
+
+// IsHidden returns true if the file or directory at p is hidden, as described in HiddenPolicy.
+// A path that does not exist is hidden if its name starts with a dot.
+func IsHidden(p string) bool {
+	if isDotName(filepath.Base(p)) {
+		return true
+	}
+	if !hiddenAttrs {
+		return false
+	}
+	info, err := os.Lstat(p)
+	return err == nil && hasHiddenAttr(p, info)
+}
+
+// isHiddenEntry returns true if the directory entry d, found at p, is hidden.
+func isHiddenEntry(p string, d fs.DirEntry) bool {
+	if isDotName(d.Name()) {
+		return true
+	}
+	if !hiddenAttrs {
+		return false
+	}
+	info, err := d.Info()
+	return err == nil && hasHiddenAttr(p, info)
+}
+
+// isDotName returns true if name starts with a dot, and is not . or ..
+func isDotName(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
@@ -0,0 +1,18 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package sys
+
+import "io/fs"
+
+// hiddenAttrs is true if the platform marks files as hidden with an attribute.
+const hiddenAttrs = false
+
+// hasHiddenAttr returns true if the file described by info, which is at p on disk, has the hidden attribute.
+// Only Windows has one.
+func hasHiddenAttr(string, fs.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func makeHiddenTree(t *testing.T) string {
+	root := filepath.Join(t.TempDir(), "tree")
+	writeTree(t, root, map[string]string{
+		".DS_Store":               "",
+		".git/config":             "",
+		"web/app.js":              "",
+		"web/.idea/workspace.xml": "",
+		"web/.env":                "",
+	})
+	return root
+}
+
+func TestHidden(t *testing.T) {
+	root := makeHiddenTree(t)
+
+	files, err := ExpandDirs([]string{root, filepath.Join(root, "web", ".env")}, &Matcher{Hidden: HiddenExclude})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := relFiles(root, files); !reflect.DeepEqual(got, []string{"web/app.js"}) {
+		t.Errorf("Got %v", got)
+	}
+	files, _ = ExpandDirs([]string{root}, &Matcher{Hidden: HiddenInclude})
+	if len(files) != 5 {
+		t.Errorf("Got %v", files)
+	}
+
+	if !IsHidden(filepath.Join(root, ".git")) || IsHidden(filepath.Join(root, "web")) || IsHidden(".") {
+		t.Error("IsHidden did not work")
+	}
+
+	dst := t.TempDir()
+	if _, err = Copy(context.Background(), dst, []string{root}, CopyOptions{Hidden: HiddenExclude}); err != nil {
+		t.Fatal(err)
+	}
+	fsys := NewMemFS()
+	if _, err = CopyFS(os.DirFS(root), ".", ".", CopyOptions{Hidden: HiddenExclude, Dest: fsys}); err != nil {
+		t.Fatal(err)
+	}
+	for f, want := range map[string]bool{
+		"web/app.js":              true,
+		".DS_Store":               false,
+		".git":                    false,
+		"web/.idea":               false,
+		"web/.idea/workspace.xml": false,
+		"web/.env":                false,
+	} {
+		if _, err := os.Stat(filepath.Join(dst, "tree", filepath.FromSlash(f))); (err == nil) != want {
+			t.Errorf("%s copied: %v, want %v", f, err == nil, want)
+		}
+		if _, err := fsys.Stat(f); (err == nil) != want {
+			t.Errorf("%s copied from the fs: %v, want %v", f, err == nil, want)
+		}
+	}
+}
+
+func TestDefaultHiddenPolicy(t *testing.T) {
+	root := makeHiddenTree(t)
+
+	// the functions without options copy hidden files
+	dst := t.TempDir()
+	if err := CopyDirectoryEx(root, dst, CopyOverwrite, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "tree", ".git")); err != nil {
+		t.Error(err)
+	}
+
+	dst = t.TempDir()
+	if _, err := CopyDirectoryContext(context.Background(), root, dst, CopyOptions{Hidden: HiddenExclude}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "tree", ".git")); err == nil {
+		t.Error("A hidden directory was copied")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "tree", "web", "app.js")); err != nil {
+		t.Error(err)
+	}
+
+	files, _ := ExpandDirs([]string{root}, &Matcher{})
+	if len(files) != 5 {
+		t.Errorf("Got %v", files)
+	}
+}
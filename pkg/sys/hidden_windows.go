@@ -0,0 +1,38 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package sys
+
+import (
+	"io/fs"
+	"path/filepath"
+	"syscall"
+)
+
+// hiddenAttrs is true if the platform marks files as hidden with an attribute.
+const hiddenAttrs = true
+
+// hasHiddenAttr returns true if the file described by info, which is at p on disk, has the hidden attribute.
+// p can be empty if the file is not on disk. The root of a volume is never hidden, even though Windows marks it so.
+func hasHiddenAttr(p string, info fs.FileInfo) bool {
+	if p != "" {
+		if abs, err := filepath.Abs(p); err == nil && filepath.Dir(abs) == abs {
+			return false
+		}
+	}
+	if d, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return d.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+	}
+	if p == "" {
+		return false
+	}
+	name, err := syscall.UTF16PtrFromString(p)
+	if err != nil {
+		return false
+	}
+	attrs, err := syscall.GetFileAttributes(name)
+	return err == nil && attrs&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+}
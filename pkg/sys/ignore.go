@@ -0,0 +1,127 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the ignore files that can be given to Matcher.IgnoreFiles and CopyOptions.IgnoreFiles.
+const (
+	// GitIgnore is the name of the files that list what git ignores.
+	GitIgnore = ".gitignore"
+	// GofileIgnore is the name of the files that list what gofile ignores. They have the same format as a .gitignore file.
+	GofileIgnore = ".gofileignore"
+)
+
+// ignorer applies the patterns in the ignore files of a directory tree.
+type ignorer struct {
+	fsys  fs.FS
+	names []string
+	// rules caches the rules of each directory, keyed by the slash separated path relative to the root
+	rules map[string][]rule
+}
+
+// newIgnorer returns an ignorer that reads the named ignore files from fsys, or nil if names is empty.
+func newIgnorer(fsys fs.FS, names []string) *ignorer {
+	if len(names) == 0 {
+		return nil
+	}
+	return &ignorer{fsys: fsys, names: names, rules: make(map[string][]rule)}
+}
+
+// newDirIgnorer returns an ignorer for the directory tree at root on disk, or nil if names is empty or
+// root is not a directory.
+func newDirIgnorer(root string, names []string) *ignorer {
+	if len(names) == 0 || !IsDir(root) {
+		return nil
+	}
+	return newIgnorer(os.DirFS(root), names)
+}
+
+// ignored returns true if the ignore files in the tree ignore name, which is relative to the root.
+// The ignore files themselves are also ignored, so that they are not copied or processed along with the files.
+//
+// As with git, the patterns in an ignore file are relative to the directory that holds it, and the patterns
+// in a deeper directory take precedence over those above it. If there is more than one ignore file in a directory,
+// the patterns of the later names take precedence.
+func (ig *ignorer) ignored(name string, isDir bool) bool {
+	if ig == nil {
+		return false
+	}
+	elems := splitPath(filepath.Clean(name))
+	if !isDir {
+		for _, n := range ig.names {
+			if elems[len(elems)-1] == n {
+				return true
+			}
+		}
+	}
+	var ignored bool
+	for i := range elems {
+		sub := strings.Join(elems[i:], "/")
+		for _, r := range ig.dirRules(strings.Join(elems[:i], "/")) {
+			if r.match(sub, isDir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// dirRules returns the rules from the ignore files in dir, which is relative to the root.
+func (ig *ignorer) dirRules(dir string) []rule {
+	if rules, ok := ig.rules[dir]; ok {
+		return rules
+	}
+	var rules []rule
+	for _, n := range ig.names {
+		p := n
+		if dir != "" {
+			p = dir + "/" + n
+		}
+		if data, err := fs.ReadFile(ig.fsys, p); err == nil {
+			rules = append(rules, parseIgnoreFile(data)...)
+		}
+	}
+	ig.rules[dir] = rules
+	return rules
+}
+
+// parseIgnoreFile returns the rules in the content of an ignore file, which has the format of a .gitignore file.
+func parseIgnoreFile(data []byte) (rules []rule) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		negate := strings.HasPrefix(line, "!")
+		p := strings.TrimPrefix(line, "!")
+		// unlike in a Matcher, a slash anywhere but the end anchors a pattern in an ignore file
+		if i := strings.Index(p, "/"); i >= 0 && i < len(p)-1 && !strings.HasPrefix(p, "/") && !strings.HasPrefix(p, doubleStar+"/") {
+			p = "/" + p
+		}
+		if negate {
+			p = "!" + p
+		}
+		// braces and regular expressions are not part of the format
+		r, err := parseGlobRule(p, false)
+		if err != nil {
+			// git skips patterns it cannot use
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return
+}
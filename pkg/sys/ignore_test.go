@@ -0,0 +1,125 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makeIgnoreTree creates a directory tree with ignore files in it, and returns its root.
+func makeIgnoreTree(t *testing.T) string {
+	root := filepath.Join(t.TempDir(), "tree")
+	files := map[string]string{
+		".gitignore":            "# build output\n/build/\n*.log\n!keep.log\nweb/dev\n",
+		".gofileignore":         "*.bak\n",
+		"main.go":               "",
+		"main.bak":              "",
+		"debug.log":             "",
+		"keep.log":              "",
+		"build/out":             "",
+		"web/index.html":        "",
+		"web/dev/app.js":        "",
+		"lib/web/dev/lib.js":    "",
+		"lib/.gitignore":        "*.tmp\n!debug.log\n",
+		"lib/a.tmp":             "",
+		"lib/debug.log":         "",
+		"lib/build/out":         "",
+		"node_modules/x/x.js":   "",
+		"node_modules/.keep":    "",
+		"sub/.gofileignore":     "/only.txt\n",
+		"sub/only.txt":          "",
+		"sub/deeper/only.txt":   "",
+		"sub/deeper/other.text": "",
+	}
+	writeTree(t, root, files)
+	return root
+}
+
+func relFiles(root string, files []string) (rel []string) {
+	for _, f := range files {
+		r, _ := filepath.Rel(root, f)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return
+}
+
+func TestIgnoreFiles(t *testing.T) {
+	root := makeIgnoreTree(t)
+
+	m := NewMatcher(nil, []string{"node_modules"})
+	m.IgnoreFiles = []string{GitIgnore, GofileIgnore}
+	files, err := ExpandFiles([]string{root}, nil, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"keep.log",
+		"lib/build/out",
+		"lib/debug.log",
+		"lib/web/dev/lib.js",
+		"main.go",
+		"sub/deeper/only.txt",
+		"sub/deeper/other.text",
+		"web/index.html",
+	}
+	if got := relFiles(root, files); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExpandFiles returned %v", got)
+	}
+
+	// without ignore files, everything is found
+	files, _ = ExpandFiles([]string{root}, nil, nil)
+	if len(files) != 20 {
+		t.Errorf("Expected 20 files, got %d", len(files))
+	}
+}
+
+func TestCopyIgnore(t *testing.T) {
+	root := makeIgnoreTree(t)
+
+	// ignore files are only read when asked for
+	dst := t.TempDir()
+	if err := CopyDirectoryEx(root, dst, CopyDoNotOverwrite, []string{"node_modules"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"main.bak", ".gofileignore", "debug.log"} {
+		if _, err := os.Stat(filepath.Join(dst, "tree", f)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	dst = t.TempDir()
+	_, err := Copy(context.Background(), dst, []string{root}, CopyOptions{IgnoreFiles: []string{GofileIgnore}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"main.bak", "sub/only.txt", ".gofileignore", "sub/.gofileignore"} {
+		if _, err := os.Stat(filepath.Join(dst, "tree", filepath.FromSlash(f))); err == nil {
+			t.Errorf("%s was copied", f)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "tree", "debug.log")); err != nil {
+		t.Error(".gitignore should only be used when it is listed")
+	}
+
+	// CopyFS reads ignore files from the source file system
+	fsys := NewMemFS()
+	_, err = CopyFS(os.DirFS(root), ".", ".", CopyOptions{Dest: fsys, IgnoreFiles: []string{GitIgnore}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fsys.Stat("build"); err == nil {
+		t.Error("An ignored directory was copied")
+	}
+	if _, err = fsys.Stat("lib/build/out"); err != nil {
+		t.Error("An anchored pattern applied to a subdirectory")
+	}
+	if _, err = fsys.Stat(".gitignore"); err == nil {
+		t.Error("An ignore file was copied")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+// Logger receives messages about the actions taken by the functions in this package.
+// The arguments following the message are alternating key and value pairs.
+// A *slog.Logger satisfies this interface.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+}
+
+// logEvent logs the event. Actions are logged at the Info level, and skipped files are logged at the Debug level
+// along with the reason they were skipped.
+func logEvent(l Logger, e Event) {
+	var args []any
+	if e.Source != "" {
+		args = append(args, "source", e.Source)
+	}
+	if e.Destination != "" {
+		args = append(args, "destination", e.Destination)
+	}
+	if e.Skipped != "" {
+		args = append(args, "reason", e.Skipped)
+		l.Debug(e.String(), args...)
+		return
+	}
+	if e.Bytes != 0 {
+		args = append(args, "bytes", e.Bytes)
+	}
+	l.Info(e.String(), args...)
+}
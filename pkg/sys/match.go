@@ -0,0 +1,497 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Matcher selects files and directories using include and exclude patterns. The patterns are glob
+// patterns as described in filepath.Match, and names are given to a Matcher as paths relative to the root
+// directory being walked or copied.
+//
+// A pattern is compared in one of these ways:
+//   - A pattern without a slash, like "*.tmp" or "vendor", is compared against the last element of a path,
+//     so it matches at any depth.
+//   - A pattern that starts with a slash, like "/web/assets/dev", is anchored, and is compared against the whole
+//     path relative to the root.
+//   - Any other pattern, like "assets/dev" or "**/dev/*.js", is compared against the end of the path as described
+//     in MatchPath, so it also matches at any depth. A ** element matches any number of directories.
+//
+// A pattern that ends with a slash, like "vendor/", only matches directories.
+//
+// Braces in a pattern are expanded as described in ExpandBraces, and the pattern matches if any of the
+// expanded patterns match, so "*.{js,css}" matches both JavaScript and CSS files.
+//
+// A pattern that starts with re: is a regular expression, as described in the regexp package, which is
+// searched for in the slash separated path relative to the root. For example, `re:\.min\.(js|css)$` matches minified
+// files. Use ^ and $ to match the whole path. Like anchored patterns, regular expressions are not compared against
+// paths given directly, since those are not relative to a root.
+//
+// A pattern that starts with ! is negated. As in a .gitignore file, the patterns in a list are compared in order, and
+// the last one that matches decides, so the excludes "*.js", "!app.js" exclude every JavaScript file except app.js.
+// Start a pattern with \! to match a name that starts with !. Since excluded directories are not searched,
+// a negated pattern cannot bring back a file inside an excluded directory.
+//
+// The patterns are parsed the first time they are needed, so they should not be changed after the Matcher is used.
+//
+// A nil *Matcher selects everything.
+type Matcher struct {
+	// Includes is a list of patterns. If not empty, only files whose names are matched by the patterns are selected.
+	// If all the patterns are negated, files that are not matched by any pattern are also selected.
+	// Directories are not compared against Includes, but directories that cannot contain an included file are
+	// not selected.
+	Includes []string
+	// Excludes is a list of patterns. Files and directories whose names are matched by the patterns are not selected.
+	Excludes []string
+	// IgnoreFiles lists the names of ignore files, like GitIgnore and GofileIgnore. When walking a directory, the
+	// patterns in the ignore files found in it and its subdirectories exclude files the way a .gitignore file does,
+	// and the ignore files themselves are excluded. If empty, ignore files are not read. The methods that are given
+	// a single name do not read ignore files.
+	IgnoreFiles []string
+	// Filter, if not nil, selects files by their type, size and modification time, and limits how deep
+	// directories are walked. The methods that are given a single name do not use it. When walking, directories
+	// are only compared against Filter.MaxDepth, so that the files inside them can be found.
+	Filter *Filter
+	// Hidden determines whether hidden files and directories are selected. Unlike the patterns, it also applies to
+	// the paths given directly.
+	Hidden HiddenPolicy
+
+	once         sync.Once
+	includeRules []rule
+	excludeRules []rule
+	// err is the error from parsing the first malformed pattern
+	err error
+}
+
+// NewMatcher returns a Matcher with the given include and exclude patterns.
+func NewMatcher(includes, excludes []string) *Matcher {
+	m := &Matcher{Includes: includes, Excludes: excludes}
+	m.compile()
+	return m
+}
+
+// compile parses the patterns, if they have not been parsed yet. Malformed patterns are left out of the rules.
+func (m *Matcher) compile() {
+	m.once.Do(func() {
+		parse := func(patterns []string) (rules []rule) {
+			for _, p := range patterns {
+				r, err := parseRule(p)
+				if err != nil {
+					if m.err == nil {
+						m.err = &PatternError{Pattern: p, Err: err}
+					}
+					continue
+				}
+				rules = append(rules, r)
+			}
+			return
+		}
+		m.includeRules = parse(m.Includes)
+		m.excludeRules = parse(m.Excludes)
+	})
+}
+
+// includes returns the parsed include patterns.
+func (m *Matcher) includes() []rule {
+	m.compile()
+	return m.includeRules
+}
+
+// excludes returns the parsed exclude patterns.
+func (m *Matcher) excludes() []rule {
+	m.compile()
+	return m.excludeRules
+}
+
+// Validate returns a *PatternError for the first include or exclude pattern that is malformed, or the error
+// from validating the Filter.
+func (m *Matcher) Validate() error {
+	if m == nil {
+		return nil
+	}
+	m.compile()
+	if m.err != nil {
+		return m.err
+	}
+	return m.Filter.Validate()
+}
+
+// ValidatePatterns returns a *PatternError for the first of the patterns that is not a valid glob pattern or
+// regular expression.
+func ValidatePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := parseRule(p); err != nil {
+			return &PatternError{Pattern: p, Err: err}
+		}
+	}
+	return nil
+}
+
+// Excluded returns true if the named file or directory is matched by the exclude patterns.
+// name is relative to the root directory.
+func (m *Matcher) Excluded(name string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	_, excluded := matchRules(name, isDir, m.excludes(), false)
+	return excluded
+}
+
+// ExcludedRoot returns true if p, which is a root directory or a file given directly rather than
+// found in a root directory, is matched by the exclude patterns. Anchored patterns and regular expressions are ignored,
+// since there is no root for p to be relative to.
+func (m *Matcher) ExcludedRoot(p string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	_, excluded := matchRules(p, isDir, m.excludes(), true)
+	return excluded
+}
+
+// Included returns true if the named file is matched by the include patterns, or if there are no include patterns.
+// name is relative to the root directory.
+func (m *Matcher) Included(name string) bool {
+	return m.included(name, false)
+}
+
+// included implements Included and the include part of MatchRoot.
+func (m *Matcher) included(name string, root bool) bool {
+	if m == nil {
+		return true
+	}
+	rules := m.includes()
+	if root {
+		rules = rootRules(rules)
+	}
+	if len(rules) == 0 {
+		return true
+	}
+	matched, included := matchRules(name, false, rules, root)
+	if !matched {
+		return allNegated(rules)
+	}
+	return included
+}
+
+// Match returns true if the named file or directory is selected. A file is selected if it is included and not
+// excluded, and a directory is selected if it is not excluded and could contain an included file.
+// name is relative to the root directory.
+func (m *Matcher) Match(name string, isDir bool) bool {
+	if m.Excluded(name, isDir) {
+		return false
+	}
+	if isDir {
+		return m.couldInclude(name)
+	}
+	return m.Included(name)
+}
+
+// MatchRoot is like Match, but for a path given directly, as described in ExcludedRoot. If all the include patterns are
+// ignored, p is included.
+func (m *Matcher) MatchRoot(p string, isDir bool) bool {
+	if m.ExcludedRoot(p, isDir) {
+		return false
+	}
+	return isDir || m.included(p, true)
+}
+
+// ignoreFiles returns the names of the ignore files to read.
+func (m *Matcher) ignoreFiles() []string {
+	if m == nil {
+		return nil
+	}
+	return m.IgnoreFiles
+}
+
+// SkipHidden returns true if the file or directory at p is hidden, and m does not select hidden files.
+func (m *Matcher) SkipHidden(p string) bool {
+	return m != nil && m.Hidden.excludes() && IsHidden(p)
+}
+
+// skipHiddenEntry is like SkipHidden for a directory entry found while walking.
+func (m *Matcher) skipHiddenEntry(p string, d fs.DirEntry) bool {
+	return m != nil && m.Hidden.excludes() && isHiddenEntry(p, d)
+}
+
+// filter returns the Filter to apply.
+func (m *Matcher) filter() *Filter {
+	if m == nil {
+		return nil
+	}
+	return m.Filter
+}
+
+// couldInclude returns true if the named directory could contain a file that is included.
+// Only anchored glob include patterns can rule out a directory.
+func (m *Matcher) couldInclude(dir string) bool {
+	if m == nil {
+		return true
+	}
+	rules := m.includes()
+	if len(rules) == 0 || allNegated(rules) {
+		return true
+	}
+	dirs := splitPath(dir)
+	for _, r := range rules {
+		if r.negate || r.dirOnly {
+			continue
+		}
+		if !r.anchored {
+			return true
+		}
+		for _, alt := range r.patterns {
+			if couldContain(splitPath(alt), dirs) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// couldContain returns true if a file in the directory whose path has the given elements could match the
+// elements of an anchored pattern.
+func couldContain(patterns, dirs []string) bool {
+	for i, d := range dirs {
+		if i >= len(patterns)-1 {
+			// the pattern ends at or above this directory
+			return false
+		}
+		if patterns[i] == doubleStar {
+			return true
+		}
+		if ok, _ := path.Match(patterns[i], d); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// rule is a parsed include or exclude pattern.
+type rule struct {
+	// patterns are the slash separated glob patterns that result from expanding the braces in the pattern,
+	// without any ! or leading or trailing slash
+	patterns []string
+	// re is the regular expression of a re: pattern, which is used instead of patterns
+	re       *regexp.Regexp
+	negate   bool
+	anchored bool
+	dirOnly  bool
+}
+
+// regexPrefix starts a pattern that is a regular expression rather than a glob pattern.
+const regexPrefix = "re:"
+
+// parseRule parses an include or exclude pattern. The only possible returned errors are filepath.ErrBadPattern
+// for a malformed glob pattern, and the error from compiling a malformed regular expression.
+func parseRule(p string) (rule, error) {
+	return parseGlobRule(p, true)
+}
+
+// parseGlobRule parses a pattern. If extended is false, braces and re: have no special meaning, as in an ignore file.
+func parseGlobRule(p string, extended bool) (r rule, err error) {
+	if strings.HasPrefix(p, "!") {
+		r.negate = true
+		p = p[1:]
+	} else if strings.HasPrefix(p, `\!`) {
+		p = p[1:]
+	}
+	if extended && strings.HasPrefix(p, regexPrefix) {
+		r.re, err = regexp.Compile(strings.TrimPrefix(p, regexPrefix))
+		return
+	}
+	p = filepath.ToSlash(p)
+	if len(p) > 1 && strings.HasSuffix(p, "/") {
+		r.dirOnly = true
+		p = strings.TrimSuffix(p, "/")
+	}
+	if strings.HasPrefix(p, "/") {
+		r.anchored = true
+		p = p[1:]
+	}
+	alts := []string{p}
+	if extended {
+		alts = ExpandBraces(p)
+	}
+	for _, alt := range alts {
+		if !r.anchored && strings.Contains(alt, "/") && !strings.HasPrefix(alt, doubleStar+"/") {
+			// patterns with a slash match at any depth
+			alt = doubleStar + "/" + alt
+		}
+		if _, err2 := path.Match(alt, ""); err2 != nil {
+			err = filepath.ErrBadPattern
+		}
+		r.patterns = append(r.patterns, alt)
+	}
+	return
+}
+
+// match returns true if the slash separated name matches the rule, ignoring negation.
+func (r rule) match(name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.re != nil {
+		return r.re.MatchString(name)
+	}
+	for _, p := range r.patterns {
+		var ok bool
+		if r.anchored || strings.Contains(p, "/") {
+			ok, _ = MatchPath(p, name)
+		} else {
+			ok, _ = path.Match(p, path.Base(name))
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRules compares name with each of the rules in turn. It returns whether any rule matched, and if so,
+// whether the last one that matched was not negated. If root is true, the rules that only apply to paths relative to
+// a root are skipped.
+func matchRules(name string, isDir bool, rules []rule, root bool) (matched bool, result bool) {
+	name = filepath.ToSlash(name)
+	for _, r := range rules {
+		if root && !r.rootable() {
+			continue
+		}
+		if r.match(name, isDir) {
+			matched = true
+			result = !r.negate
+		}
+	}
+	return
+}
+
+// rootable returns true if the rule can be compared against a path given directly. Anchored patterns and regular
+// expressions are compared against paths relative to a root, so they cannot.
+func (r rule) rootable() bool {
+	return !r.anchored && r.re == nil
+}
+
+// rootRules returns the rules that can be compared against a path given directly.
+func rootRules(rules []rule) (result []rule) {
+	for _, r := range rules {
+		if r.rootable() {
+			result = append(result, r)
+		}
+	}
+	return
+}
+
+// allNegated returns true if every one of the rules is negated.
+func allNegated(rules []rule) bool {
+	for _, r := range rules {
+		if !r.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Walk walks the file tree rooted at root in lexical order, calling fn for each file and directory selected by m,
+// including root. Directories that are not selected are not descended into. The paths given to m are relative to root,
+// the ignore files in m.IgnoreFiles are read from root and the directories inside it, the files are compared
+// against m.Filter, and hidden files and directories are skipped if m.Hidden excludes them. Errors are handled as described in fs.WalkDirFunc.
+//
+// root is a path on disk. Use ExpandFiles to start from module-aware patterns.
+func Walk(root string, m *Matcher, fn fs.WalkDirFunc) error {
+	ig := newDirIgnorer(root, m.ignoreFiles())
+	f := m.filter()
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(p, d, err)
+		}
+		var selected bool
+		var n int
+		if p == root {
+			selected = m.MatchRoot(p, d.IsDir()) && (d.IsDir() || f.MatchFile(p)) && !m.SkipHidden(p)
+		} else if m.skipHiddenEntry(p, d) {
+			selected = false
+		} else {
+			rel, _ := filepath.Rel(root, p)
+			n = depth(rel)
+			selected = m.Match(rel, d.IsDir()) && !ig.ignored(rel, d.IsDir()) && f.inDepth(n)
+			if selected && !d.IsDir() && f != nil {
+				info, err := d.Info()
+				if err != nil {
+					return fn(p, d, err)
+				}
+				selected = f.Match(info, n)
+			}
+		}
+		if !selected {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err = fn(p, d, nil); err == nil && d.IsDir() && !f.descend(n) {
+			return filepath.SkipDir
+		}
+		return err
+	})
+}
+
+// ExpandFiles returns the files that are described by the given patterns and selected by m.
+// This is the list of files that the gofile commands that process files operate on.
+//
+// The patterns are expanded as described in ModuleExpandFileList, and the result is passed to ExpandDirs.
+// If a pattern or one of the patterns in m is malformed, a *PatternError is returned.
+func ExpandFiles(patterns []string, modules map[string]string, m *Matcher) (files []string, err error) {
+	if err = m.Validate(); err != nil {
+		return
+	}
+	list, _, err := ModuleExpandFileListEx(patterns, modules)
+	if err != nil {
+		return
+	}
+	return ExpandDirs(list, m)
+}
+
+// ExpandDirs returns the paths that are selected by m, with each directory replaced by the selected files inside it.
+// Excluded directories are not walked, and directories given in paths are walked whatever m.Filter selects. Files are returned in the order of the paths, with the
+// files found in each directory in lexical order, and each file is only listed once. Paths that do not exist are
+// returned unchanged unless they are excluded, so that the caller can report them.
+//
+// If an error occurs while walking a directory, the files found so far are returned along with the error.
+func ExpandDirs(paths []string, m *Matcher) (files []string, err error) {
+	seen := make(map[string]bool)
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+
+	for _, f := range paths {
+		if !IsDir(f) {
+			if m.MatchRoot(f, false) && m.filter().MatchFile(f) && !m.SkipHidden(f) {
+				add(f)
+			}
+			continue
+		}
+		err = Walk(f, m, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				add(p)
+			}
+			return nil
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
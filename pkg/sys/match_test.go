@@ -0,0 +1,243 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher([]string{"*.txt"}, []string{"b", "no.*"})
+	tests := []struct {
+		name  string
+		isDir bool
+		want  bool
+	}{
+		{"a/t1.txt", false, true},
+		{"a/t3.abc", false, false},
+		{"c/e/no.txt", false, false},
+		{"a", true, true},
+		{"b", true, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.name, tt.isDir); got != tt.want {
+			t.Errorf("Match(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	var nilMatcher *Matcher
+	if !nilMatcher.Match("anything", false) {
+		t.Error("A nil Matcher should match everything")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	var visited []string
+	err := Walk(testDataDir1(), NewMatcher(nil, []string{"c"}), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(testDataDir1(), path)
+		visited = append(visited, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{".", "a", "a/t1.txt", "a/t2.txt", "a/t3.abc", "b", "b/t1.txt"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Walk visited %v", visited)
+	}
+}
+
+func TestExpandFiles(t *testing.T) {
+	m := NewMatcher([]string{"*.txt"}, []string{"a"})
+	files, err := ExpandFiles([]string{"testdata/t*", "testdata/dir1", "testdata/t1.txt"}, nil, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.ToSlash(f))
+	}
+	expected := []string{
+		"testdata/t1.txt",
+		"testdata/t2.txt",
+		"testdata/dir1/b/t1.txt",
+		"testdata/dir1/c/e/yes.txt",
+		"testdata/dir1/c/t1.txt",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExpandFiles returned %v", got)
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	var pe *PatternError
+	err := ValidatePatterns([]string{"*.txt", "[abc"})
+	if !errors.As(err, &pe) || pe.Pattern != "[abc" || !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("Expected a pattern error, got %v", err)
+	}
+
+	_, _, err = ModuleExpandFileListEx([]string{"testdata/t*", "testdata/[abc"}, nil)
+	if !errors.As(err, &pe) || pe.Pattern != "testdata/[abc" {
+		t.Errorf("Expected a pattern error, got %v", err)
+	}
+
+	_, err = ExpandFiles([]string{"testdata"}, nil, NewMatcher(nil, []string{"a["}))
+	if !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("Expected a bad pattern error, got %v", err)
+	}
+
+	_, err = Copy(context.Background(), "dst", []string{"testdata"}, CopyOptions{Includes: []string{"a["}})
+	if !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("Expected a bad pattern error, got %v", err)
+	}
+}
+
+func TestAnchoredPatterns(t *testing.T) {
+	m := NewMatcher(nil, []string{"/a/t1.txt", "e/", "c/t*.txt"})
+	tests := []struct {
+		name  string
+		isDir bool
+		want  bool
+	}{
+		{"a/t1.txt", false, true},
+		{"b/a/t1.txt", false, false},
+		{"c/e", true, true},
+		{"c/e", false, false},
+		{"c/t1.txt", false, true},
+		{"x/c/t1.txt", false, true},
+		{"b/t1.txt", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Excluded(filepath.FromSlash(tt.name), tt.isDir); got != tt.want {
+			t.Errorf("Excluded(%s, %v) = %v, want %v", tt.name, tt.isDir, got, tt.want)
+		}
+	}
+	if m.ExcludedRoot(filepath.FromSlash("x/a/t1.txt"), false) {
+		t.Error("Anchored patterns should not apply to a root")
+	}
+
+	files, err := ExpandFiles([]string{"testdata/dir1"}, nil, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.ToSlash(f))
+	}
+	expected := []string{
+		"testdata/dir1/a/t2.txt",
+		"testdata/dir1/a/t3.abc",
+		"testdata/dir1/b/t1.txt",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExpandFiles returned %v", got)
+	}
+
+	// the same patterns are relative to the source of a copy
+	fsys := NewMemFS()
+	result, err := Copy(context.Background(), ".", []string{testDataDir1()}, CopyOptions{Dest: fsys, Excludes: m.Excludes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Copied) != 3 {
+		t.Errorf("Copied %v", result.Copied)
+	}
+}
+
+func TestNegatedPatterns(t *testing.T) {
+	m := NewMatcher(nil, []string{"*.txt", "!t1.txt", "/b/t1.txt"})
+	for name, want := range map[string]bool{
+		"a/t1.txt": false,
+		"a/t2.txt": true,
+		"b/t1.txt": true,
+		"a/t3.abc": false,
+	} {
+		if got := m.Excluded(filepath.FromSlash(name), false); got != want {
+			t.Errorf("Excluded(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	m = NewMatcher([]string{"!*.abc"}, nil)
+	if !m.Included("t1.txt") || m.Included("t3.abc") {
+		t.Error("Negated includes did not work")
+	}
+
+	m = NewMatcher([]string{"/c/**/*.txt", "/a/t1.txt"}, nil)
+	for name, want := range map[string]bool{
+		"a":   true,
+		"a/x": false,
+		"b":   false,
+		"c":   true,
+		"c/e": true,
+	} {
+		if got := m.Match(filepath.FromSlash(name), true); got != want {
+			t.Errorf("Match(%s) = %v, want %v", name, got, want)
+		}
+	}
+	files, err := ExpandFiles([]string{"testdata/dir1"}, nil, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.ToSlash(f))
+	}
+	expected := []string{"testdata/dir1/a/t1.txt", "testdata/dir1/c/e/yes.txt", "testdata/dir1/c/t1.txt"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExpandFiles returned %v", got)
+	}
+}
+
+func TestBraceAndRegexPatterns(t *testing.T) {
+	m := NewMatcher([]string{"*.{txt,abc}"}, []string{`re:^a/.*\.abc$`, "/{b,c}/t1.txt"})
+	for name, want := range map[string]bool{
+		"a/t1.txt": true,
+		"a/t3.abc": false,
+		"b/t1.txt": false,
+		"b/t3.abc": true,
+		"c/t1.txt": false,
+		"d/t1.txt": true,
+		"d/t1.go":  false,
+	} {
+		if got := m.Match(filepath.FromSlash(name), false); got != want {
+			t.Errorf("Match(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	m = NewMatcher(nil, []string{`re:\.(txt|abc)$`, `!re:t1`})
+	if m.Excluded("t1.txt", false) || !m.Excluded("t2.txt", false) || m.Excluded("t2.go", false) {
+		t.Error("Negated regular expressions did not work")
+	}
+
+	// regular expressions match paths relative to the root, so they do not apply to the root itself
+	m = NewMatcher([]string{`re:t1\.txt$`}, []string{`re:dir1`, `re:^c/`})
+	if m.ExcludedRoot(testDataDir1(), true) || !m.MatchRoot(filepath.Join(testDataDir1(), "b", "t2.txt"), false) {
+		t.Error("A regular expression was compared against a root")
+	}
+	files, err := ExpandDirs([]string{testDataDir1()}, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || filepath.ToSlash(files[0]) != "testdata/dir1/a/t1.txt" {
+		t.Errorf("ExpandDirs returned %v", files)
+	}
+
+	err = ValidatePatterns([]string{"re:a(b"})
+	var pe *PatternError
+	if !errors.As(err, &pe) || pe.Pattern != "re:a(b" {
+		t.Errorf("Expected a pattern error, got %v", err)
+	}
+	if err = ValidatePatterns([]string{"{a,[b}"}); !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("Expected a bad pattern error, got %v", err)
+	}
+}
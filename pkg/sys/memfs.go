@@ -0,0 +1,267 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is an in-memory file system. It is a WriteFS, so it can be the destination of a copy, and it is
+// an fs.FS, so it can be read back or used as the source of CopyFS. It is safe for concurrent use.
+//
+// Names may use either slash, and are relative to the root of the file system. The root is "." or "".
+type MemFS struct {
+	mu      sync.RWMutex
+	entries map[string]*memEntry
+}
+
+type memEntry struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemFS returns an empty in-memory file system.
+func NewMemFS() *MemFS {
+	return &MemFS{entries: map[string]*memEntry{
+		".": {mode: fs.ModeDir | 0755, modTime: time.Now()},
+	}}
+}
+
+// memName converts a name to the slash separated form used as a key in a MemFS.
+func memName(name string) string {
+	n := path.Clean(strings.ReplaceAll(filepath.ToSlash(name), `\`, "/"))
+	n = strings.TrimPrefix(n, "/")
+	if n == "" {
+		n = "."
+	}
+	return n
+}
+
+func (m *MemFS) checkParent(op string, name string) error {
+	parent, ok := m.entries[path.Dir(name)]
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	if !parent.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: ErrNotDirectory}
+	}
+	return nil
+}
+
+// Create creates or truncates the named file. The file content is stored when the returned writer is closed.
+func (m *MemFS) Create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	n := memName(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkParent("create", n); err != nil {
+		return nil, err
+	}
+	if e, ok := m.entries[n]; ok && e.mode.IsDir() {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	}
+	m.entries[n] = &memEntry{mode: perm.Perm(), modTime: time.Now()}
+	return &memWriter{fsys: m, name: n}, nil
+}
+
+// Mkdir creates the named directory. Its parent must exist.
+func (m *MemFS) Mkdir(name string, perm fs.FileMode) error {
+	n := memName(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[n]; ok {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if err := m.checkParent("mkdir", n); err != nil {
+		return err
+	}
+	m.entries[n] = &memEntry{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// Stat describes the named file or directory.
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	n := memName(name)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.entries[n]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return e.info(n), nil
+}
+
+// Remove removes the named file or empty directory.
+func (m *MemFS) Remove(name string) error {
+	n := memName(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[n]
+	if !ok || n == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if e.mode.IsDir() && len(m.children(n)) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	delete(m.entries, n)
+	return nil
+}
+
+// Chmod changes the permissions of the named file or directory.
+func (m *MemFS) Chmod(name string, mode fs.FileMode) error {
+	n := memName(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[n]
+	if !ok {
+		return &fs.PathError{Op: "chmod", Path: name, Err: fs.ErrNotExist}
+	}
+	e.mode = e.mode.Type() | mode.Perm()
+	return nil
+}
+
+// Chtimes changes the modification time of the named file or directory. The access time is not recorded.
+func (m *MemFS) Chtimes(name string, _ time.Time, mtime time.Time) error {
+	n := memName(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[n]
+	if !ok {
+		return &fs.PathError{Op: "chtimes", Path: name, Err: fs.ErrNotExist}
+	}
+	e.modTime = mtime
+	return nil
+}
+
+// ReadFile returns a copy of the content of the named file. It satisfies the fs.ReadFileFS interface, so like Open,
+// name must be a valid fs.FS path.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	if e.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: ErrNotFile}
+	}
+	return bytes.Clone(e.data), nil
+}
+
+// Open opens the named file for reading. It satisfies the fs.FS interface, so name must be a valid
+// fs.FS path.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if !e.mode.IsDir() {
+		return &memFile{Reader: bytes.NewReader(e.data), info: e.info(name)}, nil
+	}
+	var list []fs.DirEntry
+	for _, child := range m.children(name) {
+		list = append(list, fs.FileInfoToDirEntry(m.entries[child].info(child)))
+	}
+	return &memDir{info: e.info(name), entries: list}, nil
+}
+
+// children returns the sorted names of the entries directly inside the named directory.
+// The caller must hold the lock.
+func (m *MemFS) children(dir string) (names []string) {
+	for n := range m.entries {
+		if n != "." && path.Dir(n) == dir {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+func (e *memEntry) info(name string) fs.FileInfo {
+	return &memInfo{name: path.Base(name), size: int64(len(e.data)), mode: e.mode, modTime: e.modTime}
+}
+
+// memWriter stores the written data in the file system when it is closed.
+type memWriter struct {
+	bytes.Buffer
+	fsys *MemFS
+	name string
+}
+
+func (w *memWriter) Close() error {
+	w.fsys.mu.Lock()
+	defer w.fsys.mu.Unlock()
+	if e, ok := w.fsys.entries[w.name]; ok {
+		e.data = w.Bytes()
+		e.modTime = time.Now()
+	}
+	return nil
+}
+
+type memInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *memInfo) Name() string       { return i.name }
+func (i *memInfo) Size() int64        { return i.size }
+func (i *memInfo) Mode() fs.FileMode  { return i.mode }
+func (i *memInfo) ModTime() time.Time { return i.modTime }
+func (i *memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *memInfo) Sys() any           { return nil }
+
+type memFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: fs.ErrInvalid}
+}
+
+func (d *memDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	d.offset += count
+	return rest[:count], nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCopyToMemFS(t *testing.T) {
+	m := NewMemFS()
+	if err := m.Mkdir("out", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := CopyOptions{Dest: m, Excludes: []string{"*.abc"}}
+	result, err := Copy(context.Background(), "out", []string{testDataDir1(), filepath.Join("testdata", "t1.txt")}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Copied) != 6 {
+		t.Errorf("Expected 6 files copied, got %d", len(result.Copied))
+	}
+
+	expected, _ := os.ReadFile(filepath.Join(testDataDir1(), "c", "e", "yes.txt"))
+	if b, err := m.ReadFile("out/dir1/c/e/yes.txt"); err != nil || string(b) != string(expected) {
+		t.Errorf("File content does not match: %v", err)
+	}
+	if _, err = m.Stat("out/dir1/a/t3.abc"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("Excluded file was copied")
+	}
+
+	// Copying to a file name in the memory file system
+	if _, err = Copy(context.Background(), "out/renamed.txt", []string{filepath.Join("testdata", "t2.txt")}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Stat("out/renamed.txt"); err != nil {
+		t.Error(err)
+	}
+
+	// Copying again skips existing files
+	opts.Overwrite = CopyDoNotOverwrite
+	result, err = Copy(context.Background(), "out", []string{filepath.Join("testdata", "t1.txt")}, opts)
+	if err != nil || len(result.Skipped) != 1 {
+		t.Errorf("Expected the existing file to be skipped: %v", err)
+	}
+
+	// A missing destination is reported the same way as on disk
+	_, err = Copy(context.Background(), "missing/", []string{filepath.Join("testdata", "t1.txt")}, opts)
+	if !errors.Is(err, ErrDestinationNotFound) {
+		t.Errorf("Expected ErrDestinationNotFound, got %v", err)
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS()
+	if err := m.Mkdir("a/b", 0755); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("Mkdir should require the parent to exist")
+	}
+	_ = m.Mkdir("a", 0755)
+	w, err := m.Create(`a\f.txt`, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("hello"))
+	_ = w.Close()
+
+	if err = m.Remove("a"); err == nil {
+		t.Error("Removed a directory that is not empty")
+	}
+	if err = fstest.TestFS(m, "a/f.txt"); err != nil {
+		t.Error(err)
+	}
+	info, _ := m.Stat("/a/f.txt")
+	if info.Mode().Perm() != 0600 || info.Size() != 5 {
+		t.Errorf("Wrong file info: %v %d", info.Mode(), info.Size())
+	}
+	if err = m.Remove("a/f.txt"); err != nil {
+		t.Error(err)
+	}
+	if err = m.Remove("a"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// WriteFS is a file system that the copy functions can write to. Set CopyOptions.Dest to copy into something
+// other than the operating system's file system. Names use the native path separator, just like the
+// destinations passed to Copy.
+//
+// Errors for names that do not exist should satisfy errors.Is(err, fs.ErrNotExist).
+type WriteFS interface {
+	// Create creates or truncates the named file with the given permissions and returns a writer for its content.
+	Create(name string, perm fs.FileMode) (io.WriteCloser, error)
+	// Mkdir creates the named directory. Its parent must exist.
+	Mkdir(name string, perm fs.FileMode) error
+	// Stat describes the named file or directory.
+	Stat(name string) (fs.FileInfo, error)
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+	// Chmod changes the permissions of the named file or directory.
+	Chmod(name string, mode fs.FileMode) error
+	// Chtimes changes the access and modification times of the named file or directory.
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+}
+
+// SymlinkFS is a WriteFS that can also create symbolic links. If CopyOptions.Dest does not implement
+// SymlinkFS, links are skipped when CopyOptions.Symlinks is SymlinkCopy.
+type SymlinkFS interface {
+	WriteFS
+	// Lstat describes the named file without following a symbolic link.
+	Lstat(name string) (fs.FileInfo, error)
+	// Symlink creates newname as a symbolic link to oldname.
+	Symlink(oldname, newname string) error
+}
+
+// OSFS is the WriteFS for the operating system's file system. It is the default destination of the copy functions.
+type OSFS struct{}
+
+// isOSFS returns true if w writes to the operating system's file system, so that its paths can be compared with
+// the paths of the sources.
+func isOSFS(w WriteFS) bool {
+	switch w.(type) {
+	case OSFS, *OSFS:
+		return true
+	}
+	return false
+}
+
+// Create creates or truncates the named file.
+func (OSFS) Create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+// Mkdir creates the named directory.
+func (OSFS) Mkdir(name string, perm fs.FileMode) error {
+	return os.Mkdir(name, perm)
+}
+
+// Stat describes the named file or directory.
+func (OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Lstat describes the named file without following a symbolic link.
+func (OSFS) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+// Remove removes the named file or empty directory.
+func (OSFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// Chmod changes the permissions of the named file or directory.
+func (OSFS) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+// Chtimes changes the access and modification times of the named file or directory.
+func (OSFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+// Symlink creates newname as a symbolic link to oldname.
+func (OSFS) Symlink(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
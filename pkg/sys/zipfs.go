@@ -0,0 +1,235 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+	"time"
+)
+
+// ZipFS is a WriteFS that writes a zip archive. Names may use either slash, and are relative to the root of the archive.
+// Call Close when done to finish the archive.
+//
+// Since a zip archive is written sequentially, a file can only be changed or removed until the next file is
+// created. After that, changing it returns ErrNotSupported. Directory entries are written when the archive is closed,
+// so their permissions and modification times can be changed at any time.
+type ZipFS struct {
+	mu      sync.Mutex
+	w       *zip.Writer
+	entries map[string]*zipEntry
+	dirs    []string
+	// pending is the name of the file that has been created, but not yet written to the archive
+	pending string
+}
+
+type zipEntry struct {
+	mode    fs.FileMode
+	modTime time.Time
+	size    int64
+	data    bytes.Buffer
+	written bool
+}
+
+// NewZipFS returns a ZipFS that writes a zip archive to w. Closing the ZipFS does not close w.
+func NewZipFS(w io.Writer) *ZipFS {
+	return &ZipFS{
+		w: zip.NewWriter(w),
+		entries: map[string]*zipEntry{
+			".": {mode: fs.ModeDir | 0755, modTime: time.Now()},
+		},
+	}
+}
+
+// flush writes the pending file to the archive. The caller must hold the lock.
+func (z *ZipFS) flush() error {
+	if z.pending == "" {
+		return nil
+	}
+	name := z.pending
+	z.pending = ""
+	e := z.entries[name]
+	h := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: e.modTime}
+	h.SetMode(e.mode)
+	w, err := z.w.CreateHeader(h)
+	if err != nil {
+		return err
+	}
+	_, err = e.data.WriteTo(w)
+	e.written = true
+	e.data = bytes.Buffer{}
+	return err
+}
+
+// entry returns the named entry, or an error if it does not exist or has already been written to the archive.
+// The caller must hold the lock.
+func (z *ZipFS) entry(op string, name string) (*zipEntry, error) {
+	e, ok := z.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	if e.written {
+		return nil, &fs.PathError{Op: op, Path: name, Err: ErrNotSupported}
+	}
+	return e, nil
+}
+
+func (z *ZipFS) checkParent(op string, name string) error {
+	parent, ok := z.entries[path.Dir(name)]
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	if !parent.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: ErrNotDirectory}
+	}
+	return nil
+}
+
+// Create adds the named file to the archive. The file can be changed until the next file is created.
+func (z *ZipFS) Create(name string, perm fs.FileMode) (io.WriteCloser, error) {
+	n := memName(name)
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if err := z.flush(); err != nil {
+		return nil, err
+	}
+	if err := z.checkParent("create", n); err != nil {
+		return nil, err
+	}
+	if _, ok := z.entries[n]; ok {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: ErrNotSupported}
+	}
+	e := &zipEntry{mode: perm.Perm(), modTime: time.Now()}
+	z.entries[n] = e
+	z.pending = n
+	return &zipWriter{fsys: z, entry: e}, nil
+}
+
+// Mkdir adds the named directory to the archive. Its parent must exist.
+func (z *ZipFS) Mkdir(name string, perm fs.FileMode) error {
+	n := memName(name)
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if _, ok := z.entries[n]; ok {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if err := z.checkParent("mkdir", n); err != nil {
+		return err
+	}
+	z.entries[n] = &zipEntry{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	z.dirs = append(z.dirs, n)
+	return nil
+}
+
+// Stat describes the named file or directory.
+func (z *ZipFS) Stat(name string) (fs.FileInfo, error) {
+	n := memName(name)
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	e, ok := z.entries[n]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memInfo{name: path.Base(n), size: e.size, mode: e.mode, modTime: e.modTime}, nil
+}
+
+// Remove removes the named file or empty directory, if it has not been written to the archive yet.
+func (z *ZipFS) Remove(name string) error {
+	n := memName(name)
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	e, err := z.entry("remove", n)
+	if err != nil {
+		return err
+	}
+	if e.mode.IsDir() {
+		for other := range z.entries {
+			if other != "." && path.Dir(other) == n {
+				return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+			}
+		}
+		for i, d := range z.dirs {
+			if d == n {
+				z.dirs = append(z.dirs[:i], z.dirs[i+1:]...)
+				break
+			}
+		}
+	}
+	if z.pending == n {
+		z.pending = ""
+	}
+	delete(z.entries, n)
+	return nil
+}
+
+// Chmod changes the permissions of the named file or directory, if it has not been written to the archive yet.
+func (z *ZipFS) Chmod(name string, mode fs.FileMode) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	e, err := z.entry("chmod", memName(name))
+	if err != nil {
+		return err
+	}
+	e.mode = e.mode.Type() | mode.Perm()
+	return nil
+}
+
+// Chtimes changes the modification time of the named file or directory, if it has not been written to the archive yet.
+// The access time is not recorded.
+func (z *ZipFS) Chtimes(name string, _ time.Time, mtime time.Time) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	e, err := z.entry("chtimes", memName(name))
+	if err != nil {
+		return err
+	}
+	e.modTime = mtime
+	return nil
+}
+
+// Close writes the remaining entries and finishes the archive.
+func (z *ZipFS) Close() error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if err := z.flush(); err != nil {
+		return err
+	}
+	for _, d := range z.dirs {
+		e := z.entries[d]
+		h := &zip.FileHeader{Name: d + "/", Modified: e.modTime}
+		h.SetMode(e.mode)
+		if _, err := z.w.CreateHeader(h); err != nil {
+			return err
+		}
+		e.written = true
+	}
+	z.dirs = nil
+	return z.w.Close()
+}
+
+// zipWriter collects the content of the pending file.
+type zipWriter struct {
+	fsys  *ZipFS
+	entry *zipEntry
+}
+
+func (w *zipWriter) Write(p []byte) (int, error) {
+	w.fsys.mu.Lock()
+	defer w.fsys.mu.Unlock()
+	if w.entry.written {
+		return 0, ErrNotSupported
+	}
+	n, err := w.entry.data.Write(p)
+	w.entry.size += int64(n)
+	return n, err
+}
+
+func (w *zipWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 Shannon Pekary. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+package sys
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyToZipFS(t *testing.T) {
+	var buf bytes.Buffer
+	z := NewZipFS(&buf)
+
+	opts := CopyOptions{Dest: z, PreserveMetadata: true}
+	result, err := Copy(context.Background(), ".", []string{testDataDir1()}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = z.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files, dirs int
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			dirs++
+		} else {
+			files++
+		}
+	}
+	if files != len(result.Copied) || dirs != len(result.DirsCreated) {
+		t.Errorf("Archive has %d files and %d dirs, expected %d and %d",
+			files, dirs, len(result.Copied), len(result.DirsCreated))
+	}
+
+	f, err := r.Open("dir1/c/e/yes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(f)
+	expected, _ := os.ReadFile(filepath.Join(testDataDir1(), "c", "e", "yes.txt"))
+	if string(b) != string(expected) {
+		t.Error("File content does not match")
+	}
+	info, _ := os.Stat(filepath.Join(testDataDir1(), "c", "e", "yes.txt"))
+	fi, _ := f.Stat()
+	if fi.ModTime().Unix() != info.ModTime().Unix() {
+		t.Errorf("Modification time was not preserved: %v %v", fi.ModTime(), info.ModTime())
+	}
+}
+
+func TestZipFSWritten(t *testing.T) {
+	z := NewZipFS(io.Discard)
+	w, _ := z.Create("a.txt", 0644)
+	_, _ = w.Write([]byte("a"))
+	_ = w.Close()
+	if err := z.Chmod("a.txt", 0600); err != nil {
+		t.Error(err)
+	}
+	_, _ = z.Create("b.txt", 0644)
+	if err := z.Chmod("a.txt", 0600); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+	if err := z.Remove("b.txt"); err != nil {
+		t.Error(err)
+	}
+	if err := z.Close(); err != nil {
+		t.Error(err)
+	}
+}